@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 
 	dbpkg "github.com/wantedly/apig/_example/db"
@@ -44,13 +45,13 @@ func GetCompanies(c *gin.Context) {
 		return
 	}
 
-	index := 0
+	lastID := ""
 
 	if len(companies) > 0 {
-		index = int(companies[len(companies)-1].ID)
+		lastID = fmt.Sprint(companies[len(companies)-1].ID)
 	}
 
-	if err := parameter.SetHeaderLink(c, index); err != nil {
+	if err := parameter.SetHeaderLink(c, lastID); err != nil {
 		c.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
@@ -117,7 +118,7 @@ func GetCompany(c *gin.Context) {
 	fields := helper.ParseFields(c.DefaultQuery("fields", "*"))
 	queryFields := helper.QueryFields(models.Company{}, fields)
 
-	if err := db.Select(queryFields).First(&company, id).Error; err != nil {
+	if err := db.Select(queryFields).Where("id = ?", id).First(&company).Error; err != nil {
 		content := gin.H{"error": "company with id#" + id + " not found"}
 		c.JSON(404, content)
 		return
@@ -180,7 +181,7 @@ func UpdateCompany(c *gin.Context) {
 	id := c.Params.ByName("id")
 	company := models.Company{}
 
-	if db.First(&company, id).Error != nil {
+	if db.Where("id = ?", id).First(&company).Error != nil {
 		content := gin.H{"error": "company with id#" + id + " not found"}
 		c.JSON(404, content)
 		return
@@ -215,7 +216,7 @@ func DeleteCompany(c *gin.Context) {
 	id := c.Params.ByName("id")
 	company := models.Company{}
 
-	if db.First(&company, id).Error != nil {
+	if db.Where("id = ?", id).First(&company).Error != nil {
 		content := gin.H{"error": "company with id#" + id + " not found"}
 		c.JSON(404, content)
 		return
@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 
 	dbpkg "github.com/wantedly/apig/_example/db"
@@ -44,13 +45,13 @@ func GetEmails(c *gin.Context) {
 		return
 	}
 
-	index := 0
+	lastID := ""
 
 	if len(emails) > 0 {
-		index = int(emails[len(emails)-1].ID)
+		lastID = fmt.Sprint(emails[len(emails)-1].ID)
 	}
 
-	if err := parameter.SetHeaderLink(c, index); err != nil {
+	if err := parameter.SetHeaderLink(c, lastID); err != nil {
 		c.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
@@ -117,7 +118,7 @@ func GetEmail(c *gin.Context) {
 	fields := helper.ParseFields(c.DefaultQuery("fields", "*"))
 	queryFields := helper.QueryFields(models.Email{}, fields)
 
-	if err := db.Select(queryFields).First(&email, id).Error; err != nil {
+	if err := db.Select(queryFields).Where("id = ?", id).First(&email).Error; err != nil {
 		content := gin.H{"error": "email with id#" + id + " not found"}
 		c.JSON(404, content)
 		return
@@ -180,7 +181,7 @@ func UpdateEmail(c *gin.Context) {
 	id := c.Params.ByName("id")
 	email := models.Email{}
 
-	if db.First(&email, id).Error != nil {
+	if db.Where("id = ?", id).First(&email).Error != nil {
 		content := gin.H{"error": "email with id#" + id + " not found"}
 		c.JSON(404, content)
 		return
@@ -215,7 +216,7 @@ func DeleteEmail(c *gin.Context) {
 	id := c.Params.ByName("id")
 	email := models.Email{}
 
-	if db.First(&email, id).Error != nil {
+	if db.Where("id = ?", id).First(&email).Error != nil {
 		content := gin.H{"error": "email with id#" + id + " not found"}
 		c.JSON(404, content)
 		return
@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 
 	dbpkg "github.com/wantedly/apig/_example/db"
@@ -44,13 +45,13 @@ func GetJobs(c *gin.Context) {
 		return
 	}
 
-	index := 0
+	lastID := ""
 
 	if len(jobs) > 0 {
-		index = int(jobs[len(jobs)-1].ID)
+		lastID = fmt.Sprint(jobs[len(jobs)-1].ID)
 	}
 
-	if err := parameter.SetHeaderLink(c, index); err != nil {
+	if err := parameter.SetHeaderLink(c, lastID); err != nil {
 		c.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
@@ -117,7 +118,7 @@ func GetJob(c *gin.Context) {
 	fields := helper.ParseFields(c.DefaultQuery("fields", "*"))
 	queryFields := helper.QueryFields(models.Job{}, fields)
 
-	if err := db.Select(queryFields).First(&job, id).Error; err != nil {
+	if err := db.Select(queryFields).Where("id = ?", id).First(&job).Error; err != nil {
 		content := gin.H{"error": "job with id#" + id + " not found"}
 		c.JSON(404, content)
 		return
@@ -180,7 +181,7 @@ func UpdateJob(c *gin.Context) {
 	id := c.Params.ByName("id")
 	job := models.Job{}
 
-	if db.First(&job, id).Error != nil {
+	if db.Where("id = ?", id).First(&job).Error != nil {
 		content := gin.H{"error": "job with id#" + id + " not found"}
 		c.JSON(404, content)
 		return
@@ -215,7 +216,7 @@ func DeleteJob(c *gin.Context) {
 	id := c.Params.ByName("id")
 	job := models.Job{}
 
-	if db.First(&job, id).Error != nil {
+	if db.Where("id = ?", id).First(&job).Error != nil {
 		content := gin.H{"error": "job with id#" + id + " not found"}
 		c.JSON(404, content)
 		return
@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 
 	dbpkg "github.com/wantedly/apig/_example/db"
@@ -44,13 +45,13 @@ func GetProfiles(c *gin.Context) {
 		return
 	}
 
-	index := 0
+	lastID := ""
 
 	if len(profiles) > 0 {
-		index = int(profiles[len(profiles)-1].ID)
+		lastID = fmt.Sprint(profiles[len(profiles)-1].ID)
 	}
 
-	if err := parameter.SetHeaderLink(c, index); err != nil {
+	if err := parameter.SetHeaderLink(c, lastID); err != nil {
 		c.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
@@ -117,7 +118,7 @@ func GetProfile(c *gin.Context) {
 	fields := helper.ParseFields(c.DefaultQuery("fields", "*"))
 	queryFields := helper.QueryFields(models.Profile{}, fields)
 
-	if err := db.Select(queryFields).First(&profile, id).Error; err != nil {
+	if err := db.Select(queryFields).Where("id = ?", id).First(&profile).Error; err != nil {
 		content := gin.H{"error": "profile with id#" + id + " not found"}
 		c.JSON(404, content)
 		return
@@ -180,7 +181,7 @@ func UpdateProfile(c *gin.Context) {
 	id := c.Params.ByName("id")
 	profile := models.Profile{}
 
-	if db.First(&profile, id).Error != nil {
+	if db.Where("id = ?", id).First(&profile).Error != nil {
 		content := gin.H{"error": "profile with id#" + id + " not found"}
 		c.JSON(404, content)
 		return
@@ -215,7 +216,7 @@ func DeleteProfile(c *gin.Context) {
 	id := c.Params.ByName("id")
 	profile := models.Profile{}
 
-	if db.First(&profile, id).Error != nil {
+	if db.Where("id = ?", id).First(&profile).Error != nil {
 		content := gin.H{"error": "profile with id#" + id + " not found"}
 		c.JSON(404, content)
 		return
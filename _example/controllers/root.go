@@ -18,6 +18,8 @@ func APIEndpoints(c *gin.Context) {
 	baseURL := fmt.Sprintf("%s://%s", reqScheme, reqHost)
 
 	resources := map[string]string{
+		"comments_url":  baseURL + "/api/comments",
+		"comment_url":   baseURL + "/api/comments/{id}",
 		"companies_url": baseURL + "/api/companies",
 		"company_url":   baseURL + "/api/companies/{id}",
 		"emails_url":    baseURL + "/api/emails",
@@ -26,6 +28,8 @@ func APIEndpoints(c *gin.Context) {
 		"job_url":       baseURL + "/api/jobs/{id}",
 		"profiles_url":  baseURL + "/api/profiles",
 		"profile_url":   baseURL + "/api/profiles/{id}",
+		"tags_url":      baseURL + "/api/tags",
+		"tag_url":       baseURL + "/api/tags/{id}",
 		"users_url":     baseURL + "/api/users",
 		"user_url":      baseURL + "/api/users/{id}",
 	}
@@ -0,0 +1,324 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	dbpkg "github.com/wantedly/apig/_example/db"
+	"github.com/wantedly/apig/_example/helper"
+	"github.com/wantedly/apig/_example/models"
+	"github.com/wantedly/apig/_example/version"
+
+	"github.com/gin-gonic/gin"
+)
+
+func GetTags(c *gin.Context) {
+	ver, err := version.New(c)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	db := dbpkg.DBInstance(c)
+	parameter, err := dbpkg.NewParameter(c, models.Tag{})
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	db, err = parameter.Paginate(db)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	db = parameter.SetPreloads(db)
+	db = parameter.SortRecords(db)
+	db = parameter.FilterFields(db)
+	tags := []models.Tag{}
+	fields := helper.ParseFields(c.DefaultQuery("fields", "*"))
+	queryFields := helper.QueryFields(models.Tag{}, fields)
+
+	if err := db.Select(queryFields).Find(&tags).Error; err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	lastID := ""
+
+	if len(tags) > 0 {
+		lastID = fmt.Sprint(tags[len(tags)-1].ID)
+	}
+
+	if err := parameter.SetHeaderLink(c, lastID); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	if version.Range("1.0.0", "<=", ver) && version.Range(ver, "<", "2.0.0") {
+		// conditional branch by version.
+		// 1.0.0 <= this version < 2.0.0 !!
+	}
+
+	if _, ok := c.GetQuery("stream"); ok {
+		enc := json.NewEncoder(c.Writer)
+		c.Status(200)
+
+		for _, tag := range tags {
+			fieldMap, err := helper.FieldToMap(tag, fields)
+			if err != nil {
+				c.JSON(400, gin.H{"error": err.Error()})
+				return
+			}
+
+			if err := enc.Encode(fieldMap); err != nil {
+				c.JSON(400, gin.H{"error": err.Error()})
+				return
+			}
+		}
+	} else {
+		fieldMaps := []map[string]interface{}{}
+
+		for _, tag := range tags {
+			fieldMap, err := helper.FieldToMap(tag, fields)
+			if err != nil {
+				c.JSON(400, gin.H{"error": err.Error()})
+				return
+			}
+
+			fieldMaps = append(fieldMaps, fieldMap)
+		}
+
+		if _, ok := c.GetQuery("pretty"); ok {
+			c.IndentedJSON(200, fieldMaps)
+		} else {
+			c.JSON(200, fieldMaps)
+		}
+	}
+}
+
+func GetTag(c *gin.Context) {
+	ver, err := version.New(c)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	db := dbpkg.DBInstance(c)
+	parameter, err := dbpkg.NewParameter(c, models.Tag{})
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	db = parameter.SetPreloads(db)
+	tag := models.Tag{}
+	id := c.Params.ByName("id")
+	fields := helper.ParseFields(c.DefaultQuery("fields", "*"))
+	queryFields := helper.QueryFields(models.Tag{}, fields)
+
+	if err := db.Select(queryFields).Where("id = ?", id).First(&tag).Error; err != nil {
+		content := gin.H{"error": "tag with id#" + id + " not found"}
+		c.JSON(404, content)
+		return
+	}
+
+	fieldMap, err := helper.FieldToMap(tag, fields)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	if version.Range("1.0.0", "<=", ver) && version.Range(ver, "<", "2.0.0") {
+		// conditional branch by version.
+		// 1.0.0 <= this version < 2.0.0 !!
+	}
+
+	if _, ok := c.GetQuery("pretty"); ok {
+		c.IndentedJSON(200, fieldMap)
+	} else {
+		c.JSON(200, fieldMap)
+	}
+}
+
+func CreateTag(c *gin.Context) {
+	ver, err := version.New(c)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	db := dbpkg.DBInstance(c)
+	tag := models.Tag{}
+
+	if err := c.Bind(&tag); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := helper.ValidateRequired(tag); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := db.Create(&tag).Error; err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	if version.Range("1.0.0", "<=", ver) && version.Range(ver, "<", "2.0.0") {
+		// conditional branch by version.
+		// 1.0.0 <= this version < 2.0.0 !!
+	}
+
+	c.JSON(201, tag)
+}
+
+func UpdateTag(c *gin.Context) {
+	ver, err := version.New(c)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	db := dbpkg.DBInstance(c)
+	id := c.Params.ByName("id")
+	tag := models.Tag{}
+
+	if db.Where("id = ?", id).First(&tag).Error != nil {
+		content := gin.H{"error": "tag with id#" + id + " not found"}
+		c.JSON(404, content)
+		return
+	}
+
+	if err := c.Bind(&tag); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := helper.ValidateRequired(tag); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := db.Save(&tag).Error; err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	if version.Range("1.0.0", "<=", ver) && version.Range(ver, "<", "2.0.0") {
+		// conditional branch by version.
+		// 1.0.0 <= this version < 2.0.0 !!
+	}
+
+	c.JSON(200, tag)
+}
+
+func DeleteTag(c *gin.Context) {
+	ver, err := version.New(c)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	db := dbpkg.DBInstance(c)
+	id := c.Params.ByName("id")
+	tag := models.Tag{}
+
+	if db.Where("id = ?", id).First(&tag).Error != nil {
+		content := gin.H{"error": "tag with id#" + id + " not found"}
+		c.JSON(404, content)
+		return
+	}
+
+	if err := db.Delete(&tag).Error; err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	if version.Range("1.0.0", "<=", ver) && version.Range(ver, "<", "2.0.0") {
+		// conditional branch by version.
+		// 1.0.0 <= this version < 2.0.0 !!
+	}
+
+	c.Writer.WriteHeader(http.StatusNoContent)
+}
+
+func AddTagUser(c *gin.Context) {
+	ver, err := version.New(c)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	db := dbpkg.DBInstance(c)
+	id := c.Params.ByName("id")
+	tag := models.Tag{}
+
+	if db.Where("id = ?", id).First(&tag).Error != nil {
+		content := gin.H{"error": "tag with id#" + id + " not found"}
+		c.JSON(404, content)
+		return
+	}
+
+	linkedID := c.Params.ByName("user_id")
+	linked := models.User{}
+
+	if db.Where("id = ?", linkedID).First(&linked).Error != nil {
+		content := gin.H{"error": "user with id#" + linkedID + " not found"}
+		c.JSON(404, content)
+		return
+	}
+
+	if err := db.Model(&tag).Association("Users").Append(&linked).Error; err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	if version.Range("1.0.0", "<=", ver) && version.Range(ver, "<", "2.0.0") {
+		// conditional branch by version.
+		// 1.0.0 <= this version < 2.0.0 !!
+	}
+
+	c.Writer.WriteHeader(http.StatusNoContent)
+}
+
+func RemoveTagUser(c *gin.Context) {
+	ver, err := version.New(c)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	db := dbpkg.DBInstance(c)
+	id := c.Params.ByName("id")
+	tag := models.Tag{}
+
+	if db.Where("id = ?", id).First(&tag).Error != nil {
+		content := gin.H{"error": "tag with id#" + id + " not found"}
+		c.JSON(404, content)
+		return
+	}
+
+	linkedID := c.Params.ByName("user_id")
+	linked := models.User{}
+
+	if db.Where("id = ?", linkedID).First(&linked).Error != nil {
+		content := gin.H{"error": "user with id#" + linkedID + " not found"}
+		c.JSON(404, content)
+		return
+	}
+
+	if err := db.Model(&tag).Association("Users").Delete(&linked).Error; err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	if version.Range("1.0.0", "<=", ver) && version.Range(ver, "<", "2.0.0") {
+		// conditional branch by version.
+		// 1.0.0 <= this version < 2.0.0 !!
+	}
+
+	c.Writer.WriteHeader(http.StatusNoContent)
+}
@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 
 	dbpkg "github.com/wantedly/apig/_example/db"
@@ -44,13 +45,13 @@ func GetUsers(c *gin.Context) {
 		return
 	}
 
-	index := 0
+	lastID := ""
 
 	if len(users) > 0 {
-		index = int(users[len(users)-1].ID)
+		lastID = fmt.Sprint(users[len(users)-1].ID)
 	}
 
-	if err := parameter.SetHeaderLink(c, index); err != nil {
+	if err := parameter.SetHeaderLink(c, lastID); err != nil {
 		c.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
@@ -117,7 +118,7 @@ func GetUser(c *gin.Context) {
 	fields := helper.ParseFields(c.DefaultQuery("fields", "*"))
 	queryFields := helper.QueryFields(models.User{}, fields)
 
-	if err := db.Select(queryFields).First(&user, id).Error; err != nil {
+	if err := db.Select(queryFields).Where("id = ?", id).First(&user).Error; err != nil {
 		content := gin.H{"error": "user with id#" + id + " not found"}
 		c.JSON(404, content)
 		return
@@ -180,7 +181,7 @@ func UpdateUser(c *gin.Context) {
 	id := c.Params.ByName("id")
 	user := models.User{}
 
-	if db.First(&user, id).Error != nil {
+	if db.Where("id = ?", id).First(&user).Error != nil {
 		content := gin.H{"error": "user with id#" + id + " not found"}
 		c.JSON(404, content)
 		return
@@ -215,7 +216,7 @@ func DeleteUser(c *gin.Context) {
 	id := c.Params.ByName("id")
 	user := models.User{}
 
-	if db.First(&user, id).Error != nil {
+	if db.Where("id = ?", id).First(&user).Error != nil {
 		content := gin.H{"error": "user with id#" + id + " not found"}
 		c.JSON(404, content)
 		return
@@ -233,3 +234,81 @@ func DeleteUser(c *gin.Context) {
 
 	c.Writer.WriteHeader(http.StatusNoContent)
 }
+
+func AddUserTag(c *gin.Context) {
+	ver, err := version.New(c)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	db := dbpkg.DBInstance(c)
+	id := c.Params.ByName("id")
+	user := models.User{}
+
+	if db.Where("id = ?", id).First(&user).Error != nil {
+		content := gin.H{"error": "user with id#" + id + " not found"}
+		c.JSON(404, content)
+		return
+	}
+
+	linkedID := c.Params.ByName("tag_id")
+	linked := models.Tag{}
+
+	if db.Where("id = ?", linkedID).First(&linked).Error != nil {
+		content := gin.H{"error": "tag with id#" + linkedID + " not found"}
+		c.JSON(404, content)
+		return
+	}
+
+	if err := db.Model(&user).Association("Tags").Append(&linked).Error; err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	if version.Range("1.0.0", "<=", ver) && version.Range(ver, "<", "2.0.0") {
+		// conditional branch by version.
+		// 1.0.0 <= this version < 2.0.0 !!
+	}
+
+	c.Writer.WriteHeader(http.StatusNoContent)
+}
+
+func RemoveUserTag(c *gin.Context) {
+	ver, err := version.New(c)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	db := dbpkg.DBInstance(c)
+	id := c.Params.ByName("id")
+	user := models.User{}
+
+	if db.Where("id = ?", id).First(&user).Error != nil {
+		content := gin.H{"error": "user with id#" + id + " not found"}
+		c.JSON(404, content)
+		return
+	}
+
+	linkedID := c.Params.ByName("tag_id")
+	linked := models.Tag{}
+
+	if db.Where("id = ?", linkedID).First(&linked).Error != nil {
+		content := gin.H{"error": "tag with id#" + linkedID + " not found"}
+		c.JSON(404, content)
+		return
+	}
+
+	if err := db.Model(&user).Association("Tags").Delete(&linked).Error; err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	if version.Range("1.0.0", "<=", ver) && version.Range(ver, "<", "2.0.0") {
+		// conditional branch by version.
+		// 1.0.0 <= this version < 2.0.0 !!
+	}
+
+	c.Writer.WriteHeader(http.StatusNoContent)
+}
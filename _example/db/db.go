@@ -29,10 +29,12 @@ func Connect() *gorm.DB {
 
 	if os.Getenv("AUTOMIGRATE") == "1" {
 		db.AutoMigrate(
+			&models.Comment{},
 			&models.Company{},
 			&models.Email{},
 			&models.Job{},
 			&models.Profile{},
+			&models.Tag{},
 			&models.User{},
 		)
 	}
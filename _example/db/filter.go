@@ -1,43 +1,79 @@
 package db
 
 import (
+	"errors"
 	"fmt"
-	"reflect"
+	"regexp"
 	"strings"
 
+	"github.com/wantedly/apig/_example/helper"
+
 	"github.com/gin-gonic/gin"
 	"github.com/jinzhu/gorm"
 )
 
-func filterToMap(c *gin.Context, model interface{}) map[string]string {
-	var jsonTag, jsonKey string
+var jsonPath = regexp.MustCompile(`^[A-Za-z0-9_]+(\.[A-Za-z0-9_]+)*$`)
+
+// filterToMap returns the filters given by q[key]=values, where key is the JSON key or the column name of the field
+// including the ones of embedded structs. The values in JSON documents are filtered by their path, e.g.
+// q[metadata.plan]=free. An error is returned for the fields which cannot be filtered.
+func filterToMap(c *gin.Context, model interface{}) (map[string]string, error) {
 	filters := make(map[string]string)
-	ts := reflect.TypeOf(model)
 
-	for i := 0; i < ts.NumField(); i++ {
-		f := ts.Field(i)
-		jsonKey = f.Name
+	for key, values := range c.Request.URL.Query() {
+		if !strings.HasPrefix(key, "q[") || !strings.HasSuffix(key, "]") || len(values) == 0 {
+			continue
+		}
+
+		name := key[len("q[") : len(key)-1]
+		parts := strings.SplitN(name, ".", 2)
 
-		if jsonTag = f.Tag.Get("json"); jsonTag != "" {
-			jsonKey = strings.Split(jsonTag, ",")[0]
+		f, ok := helper.FieldByKey(model, parts[0])
+		if !ok || !helper.Filterable(model, parts[0]) {
+			return nil, errors.New("Invalid Parameter. The specified field cannot be filtered.")
 		}
 
-		filters[jsonKey] = c.Query("q[" + jsonKey + "]")
+		if len(parts) == 2 && (!helper.IsJSON(f.Type) || !jsonPath.MatchString(parts[1])) {
+			return nil, errors.New("Invalid Parameter. The specified field cannot be filtered.")
+		}
+
+		filters[name] = values[0]
 	}
 
-	return filters
+	return filters, nil
 }
 
 func (self *Parameter) FilterFields(db *gorm.DB) *gorm.DB {
 	for k, v := range self.Filters {
-		if v != "" {
-			db = db.Where(fmt.Sprintf("%s IN (?)", k), strings.Split(v, ","))
+		if v == "" {
+			continue
 		}
+
+		parts := strings.SplitN(k, ".", 2)
+		column := self.column(parts[0])
+
+		if len(parts) == 2 {
+			column = jsonExpression(db.Dialect().GetName(), column, strings.Split(parts[1], "."))
+		}
+
+		db = db.Where(fmt.Sprintf("%s IN (?)", column), strings.Split(v, ","))
 	}
 
 	return db
 }
 
+// jsonExpression returns the SQL expression which extracts the value at the path of the JSON column as text.
+func jsonExpression(dialect, column string, path []string) string {
+	switch dialect {
+	case "postgres":
+		return fmt.Sprintf("%s #>> '{%s}'", column, strings.Join(path, ","))
+	case "mysql":
+		return fmt.Sprintf("%s ->> '$.%s'", column, strings.Join(path, "."))
+	}
+
+	return fmt.Sprintf("CAST(json_extract(%s, '$.%s') AS TEXT)", column, strings.Join(path, "."))
+}
+
 func (self *Parameter) GetRawFilterQuery() string {
 	var s string
 
@@ -13,29 +13,33 @@ type User struct {
 	Engaged bool   `json:"engaged,omitempty" form:"engaged"`
 }
 
-func contains(ss map[string]string, s string) bool {
-	_, ok := ss[s]
+type Timestamps struct {
+	CreatedAt string `json:"created_at,omitempty" form:"created_at"`
+}
 
-	return ok
+type Plan struct {
+	ID       uint                   `json:"id,omitempty" form:"id"`
+	Metadata map[string]interface{} `json:"metadata,omitempty" form:"metadata"`
+	Price    uint                   `json:"price,omitempty" form:"price" apig:"writeonly"`
+	*Timestamps
 }
 
-func TestFilterToMap(t *testing.T) {
-	req, _ := http.NewRequest("GET", "/?q[id]=1,5,100&q[name]=hoge,fuga&q[unexisted_field]=null", nil)
-	c := &gin.Context{
-		Request: req,
-	}
-	value := filterToMap(c, User{})
+func newContext(query string) *gin.Context {
+	req, _ := http.NewRequest("GET", "/?"+query, nil)
 
-	if !contains(value, "id") {
-		t.Fatalf("Filter should have `id` key.")
+	return &gin.Context{
+		Request: req,
 	}
+}
 
-	if !contains(value, "name") {
-		t.Fatalf("Filter should have `name` key.")
+func TestFilterToMap(t *testing.T) {
+	value, err := filterToMap(newContext("q[id]=1,5,100&q[name]=hoge,fuga&limit=10"), User{})
+	if err != nil {
+		t.Fatalf("Error should not be raised: %s", err)
 	}
 
-	if contains(value, "unexisted_field") {
-		t.Fatalf("Filter should not have `unexisted_field` key.")
+	if len(value) != 2 {
+		t.Fatalf("Filter should have only `id` and `name` keys. actual: %v", value)
 	}
 
 	if value["id"] != "1,5,100" {
@@ -43,6 +47,54 @@ func TestFilterToMap(t *testing.T) {
 	}
 
 	if value["name"] != "hoge,fuga" {
-		t.Fatalf("filters[\"name\"] expected: `hoge,fuga`, actual: %s", value["id"])
+		t.Fatalf("filters[\"name\"] expected: `hoge,fuga`, actual: %s", value["name"])
+	}
+}
+
+func TestFilterToMap_Invalid(t *testing.T) {
+	for _, query := range []string{"q[unexisted_field]=null", "q[price]=100", "q[metadata.x']=1", "q[id.plan]=1"} {
+		if _, err := filterToMap(newContext(query), Plan{}); err == nil {
+			t.Fatalf("Error should be raised for %s.", query)
+		}
+	}
+}
+
+func TestFilterToMap_Embedded(t *testing.T) {
+	value, err := filterToMap(newContext("q[created_at]=2016-01-01"), Plan{})
+	if err != nil {
+		t.Fatalf("Error should not be raised: %s", err)
+	}
+
+	if value["created_at"] != "2016-01-01" {
+		t.Fatalf("filters[\"created_at\"] expected: `2016-01-01`, actual: %s", value["created_at"])
+	}
+}
+
+func TestFilterToMap_JSON(t *testing.T) {
+	value, err := filterToMap(newContext("q[metadata.plan]=free,pro&q[metadata.org.name]=wantedly"), Plan{})
+	if err != nil {
+		t.Fatalf("Error should not be raised: %s", err)
+	}
+
+	if value["metadata.plan"] != "free,pro" {
+		t.Fatalf("filters[\"metadata.plan\"] expected: `free,pro`, actual: %s", value["metadata.plan"])
+	}
+
+	if value["metadata.org.name"] != "wantedly" {
+		t.Fatalf("filters[\"metadata.org.name\"] expected: `wantedly`, actual: %s", value["metadata.org.name"])
+	}
+}
+
+func TestJSONExpression(t *testing.T) {
+	cases := map[string]string{
+		"postgres": "metadata #>> '{org,name}'",
+		"mysql":    "metadata ->> '$.org.name'",
+		"sqlite3":  "CAST(json_extract(metadata, '$.org.name') AS TEXT)",
+	}
+
+	for dialect, expected := range cases {
+		if result := jsonExpression(dialect, "metadata", []string{"org", "name"}); result != expected {
+			t.Fatalf("Incorrect expression for %s. expected: %s, actual: %s", dialect, expected, result)
+		}
 	}
 }
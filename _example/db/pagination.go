@@ -15,16 +15,16 @@ func (self *Parameter) Paginate(db *gorm.DB) (*gorm.DB, error) {
 
 	if self.IsLastID {
 		if self.Order == "asc" {
-			return db.Where("id > ?", self.LastID).Limit(self.Limit).Order("id asc"), nil
+			return db.Where(fmt.Sprintf("%s > ?", self.PrimaryKey), self.LastID).Limit(self.Limit).Order(self.PrimaryKey + " asc"), nil
 		}
 
-		return db.Where("id < ?", self.LastID).Limit(self.Limit).Order("id desc"), nil
+		return db.Where(fmt.Sprintf("%s < ?", self.PrimaryKey), self.LastID).Limit(self.Limit).Order(self.PrimaryKey + " desc"), nil
 	}
 
 	return db.Offset(self.Limit * (self.Page - 1)).Limit(self.Limit), nil
 }
 
-func (self *Parameter) SetHeaderLink(c *gin.Context, index int) error {
+func (self *Parameter) SetHeaderLink(c *gin.Context, lastID string) error {
 	if self == nil {
 		return errors.New("Parameter struct got nil.")
 	}
@@ -49,7 +49,12 @@ func (self *Parameter) SetHeaderLink(c *gin.Context, index int) error {
 	}
 
 	if self.IsLastID {
-		c.Header("Link", fmt.Sprintf("<%s://%v%v?limit=%v%s%s&last_id=%v&order=%v%s>; rel=\"next\"", reqScheme, c.Request.Host, c.Request.URL.Path, self.Limit, filters, preloads, index, self.Order, pretty))
+		// no records are left after the cursor
+		if lastID == "" {
+			lastID = self.LastID
+		}
+
+		c.Header("Link", fmt.Sprintf("<%s://%v%v?limit=%v%s%s&last_id=%v&order=%v%s>; rel=\"next\"", reqScheme, c.Request.Host, c.Request.URL.Path, self.Limit, filters, preloads, lastID, self.Order, pretty))
 		return nil
 	}
 
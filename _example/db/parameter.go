@@ -4,6 +4,8 @@ import (
 	"math"
 	"strconv"
 
+	"github.com/wantedly/apig/_example/helper"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -14,14 +16,18 @@ const (
 )
 
 type Parameter struct {
-	Filters  map[string]string
-	Preloads string
-	Sort     string
-	Limit    int
-	Page     int
-	LastID   int
-	Order    string
-	IsLastID bool
+	Filters    map[string]string
+	Preloads   string
+	Sort       string
+	Limit      int
+	Page       int
+	PrimaryKey string
+	LastID     string
+	Order      string
+	IsLastID   bool
+
+	// model resolves the keys of Filters and Sort to the columns
+	model interface{}
 }
 
 func NewParameter(c *gin.Context, model interface{}) (*Parameter, error) {
@@ -35,10 +41,20 @@ func NewParameter(c *gin.Context, model interface{}) (*Parameter, error) {
 }
 
 func (self *Parameter) initialize(c *gin.Context, model interface{}) error {
-	self.Filters = filterToMap(c, model)
+	filters, err := filterToMap(c, model)
+	if err != nil {
+		return err
+	}
+
+	self.model = model
+	self.Filters = filters
 	self.Preloads = c.Query("preloads")
 	self.Sort = c.Query("sort")
 
+	if err := validateSort(self.Sort, model); err != nil {
+		return err
+	}
+
 	limit, err := validate(c.DefaultQuery("limit", defaultLimit))
 	if err != nil {
 		return err
@@ -51,20 +67,39 @@ func (self *Parameter) initialize(c *gin.Context, model interface{}) error {
 	}
 
 	self.Page = int(math.Max(1, float64(page)))
-	lastID, err := validate(c.Query("last_id"))
-	if err != nil {
-		return err
-	}
+	primaryKey, numeric := helper.PrimaryKey(model)
+	self.PrimaryKey = primaryKey
 
-	if lastID != -1 {
+	// records of composite primary keys are paged by offset as the cursor cannot hold all of the keys
+	if lastID := c.Query("last_id"); lastID != "" && !helper.IsCompositeKey(model) {
 		self.IsLastID = true
-		self.LastID = int(math.Max(0, float64(lastID)))
+		self.LastID = lastID
+
+		if numeric {
+			id, err := validate(lastID)
+			if err != nil {
+				return err
+			}
+
+			self.LastID = strconv.Itoa(int(math.Max(0, float64(id))))
+		}
 	}
 
 	self.Order = c.DefaultQuery("order", defaultOrder)
 	return nil
 }
 
+// column returns the column of the field with the JSON key or the column name.
+func (self *Parameter) column(key string) string {
+	if self.model != nil {
+		if column, ok := helper.ColumnName(self.model, key); ok {
+			return column
+		}
+	}
+
+	return key
+}
+
 func validate(s string) (int, error) {
 	if s == "" {
 		return -1, nil
@@ -0,0 +1,52 @@
+package db
+
+import (
+	"testing"
+)
+
+type Membership struct {
+	UserID    uint `gorm:"primary_key;auto_increment:false" json:"user_id,omitempty" form:"user_id"`
+	CompanyID uint `gorm:"primary_key;auto_increment:false" json:"company_id,omitempty" form:"company_id"`
+}
+
+type Profile struct {
+	ID   uint   `json:"id,omitempty" form:"id"`
+	Name string `json:"name,omitempty" form:"name" gorm:"column:profile_name"`
+}
+
+func TestNewParameter_Column(t *testing.T) {
+	parameter, err := NewParameter(newContext("q[name]=hoge&sort=-name"), Profile{})
+	if err != nil {
+		t.Fatalf("Error should not be raised: %s", err)
+	}
+
+	if column := parameter.column("name"); column != "profile_name" {
+		t.Fatalf("JSON key should be resolved to the column. expected: profile_name, actual: %s", column)
+	}
+
+	if column := parameter.column("id"); column != "id" {
+		t.Fatalf("Incorrect column. expected: id, actual: %s", column)
+	}
+}
+
+func TestNewParameter_LastID(t *testing.T) {
+	parameter, err := NewParameter(newContext("last_id=10&limit=5"), User{})
+	if err != nil {
+		t.Fatalf("Error should not be raised: %s", err)
+	}
+
+	if !parameter.IsLastID || parameter.LastID != "10" || parameter.PrimaryKey != "id" {
+		t.Fatalf("Records should be paged by id. actual: %#v", parameter)
+	}
+}
+
+func TestNewParameter_CompositeKey(t *testing.T) {
+	parameter, err := NewParameter(newContext("last_id=10&page=2"), Membership{})
+	if err != nil {
+		t.Fatalf("Error should not be raised: %s", err)
+	}
+
+	if parameter.IsLastID || parameter.Page != 2 {
+		t.Fatalf("Records of composite primary keys should be paged by offset. actual: %#v", parameter)
+	}
+}
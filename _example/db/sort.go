@@ -1,8 +1,11 @@
 package db
 
 import (
+	"errors"
 	"strings"
 
+	"github.com/wantedly/apig/_example/helper"
+
 	"github.com/jinzhu/gorm"
 )
 
@@ -14,13 +17,28 @@ func convertPrefixToQuery(sort string) string {
 	}
 }
 
+func validateSort(sort string, model interface{}) error {
+	if sort == "" {
+		return nil
+	}
+
+	for _, s := range strings.Split(sort, ",") {
+		if !helper.Sortable(model, strings.TrimLeft(s, "- ")) {
+			return errors.New("Invalid Parameter. The specified field cannot be sorted.")
+		}
+	}
+
+	return nil
+}
+
 func (self *Parameter) SortRecords(db *gorm.DB) *gorm.DB {
 	if self.Sort == "" {
 		return db
 	}
 
 	for _, sort := range strings.Split(self.Sort, ",") {
-		db = db.Order(convertPrefixToQuery(sort))
+		key := strings.TrimLeft(sort, "- ")
+		db = db.Order(convertPrefixToQuery(sort[:len(sort)-len(key)] + self.column(key)))
 	}
 
 	return db
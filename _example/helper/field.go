@@ -1,6 +1,7 @@
 package helper
 
 import (
+	"encoding/json"
 	"errors"
 	"reflect"
 	"strings"
@@ -37,15 +38,178 @@ func merge(m1, m2 map[string]interface{}) map[string]interface{} {
 	return result
 }
 
+// structFields returns the fields of the struct type. Fields of embedded structs without JSON name
+// are promoted to the struct in the same way as encoding/json, so that Index holds the index sequence.
+func structFields(t reflect.Type) []reflect.StructField {
+	result := []reflect.StructField{}
+	names := make(map[string]bool)
+
+	for i := 0; i < t.NumField(); i++ {
+		if !isEmbeddedStruct(t.Field(i)) {
+			names[t.Field(i).Name] = true
+		}
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		if !isEmbeddedStruct(f) {
+			result = append(result, f)
+			continue
+		}
+
+		ft := f.Type
+
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+
+		for _, ef := range structFields(ft) {
+			if names[ef.Name] {
+				continue
+			}
+
+			names[ef.Name] = true
+			ef.Index = append([]int{i}, ef.Index...)
+			result = append(result, ef)
+		}
+	}
+
+	return result
+}
+
+func isEmbeddedStruct(f reflect.StructField) bool {
+	if !f.Anonymous || strings.Split(f.Tag.Get("json"), ",")[0] != "" {
+		return false
+	}
+
+	t := f.Type
+
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t.Kind() == reflect.Struct
+}
+
+// fieldByIndex is like reflect.Value.FieldByIndex, but returns false instead of panic
+// when it goes through a nil embedded struct pointer.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+
+			v = v.Elem()
+		}
+
+		v = v.Field(x)
+	}
+
+	return v, true
+}
+
+// gormSetting returns the value of the key in gorm struct tag, e.g. `gorm:"foreignkey:AuthorID"`.
+func gormSetting(tag reflect.StructTag, key string) (string, bool) {
+	for _, s := range strings.Split(tag.Get("gorm"), ";") {
+		kv := strings.SplitN(s, ":", 2)
+
+		if !strings.EqualFold(strings.TrimSpace(kv[0]), key) {
+			continue
+		}
+
+		if len(kv) == 2 {
+			return strings.TrimSpace(kv[1]), true
+		}
+
+		return "", true
+	}
+
+	return "", false
+}
+
+// belongsToForeignKey returns the column of the foreign key when the field is a belongs-to association.
+// The foreign key specified by gorm tag is preferred to <Field>ID.
+func belongsToForeignKey(t reflect.Type, f reflect.StructField) (string, bool) {
+	name := f.Name + "ID"
+
+	if foreignKey, ok := gormSetting(f.Tag, "foreignkey"); ok {
+		name = foreignKey
+	}
+
+	fk, ok := t.FieldByName(name)
+	if !ok {
+		return "", false
+	}
+
+	if column, ok := gormSetting(fk.Tag, "column"); ok {
+		return column, true
+	}
+
+	return snaker.CamelToSnake(fk.Name), true
+}
+
+// PrimaryKey returns the column of the primary key of the model and whether it holds integers.
+// The field tagged with `gorm:"primary_key"` is preferred to the field named ID as gorm does.
+func PrimaryKey(model interface{}) (string, bool) {
+	fields := structFields(reflect.TypeOf(model))
+
+	for _, f := range fields {
+		if _, ok := gormSetting(f.Tag, "primary_key"); ok {
+			return columnName(f), isInteger(f.Type)
+		}
+	}
+
+	for _, f := range fields {
+		if f.Name == "ID" {
+			return columnName(f), isInteger(f.Type)
+		}
+	}
+
+	return "id", true
+}
+
+// IsCompositeKey reports whether the model has multiple fields tagged with `gorm:"primary_key"`.
+func IsCompositeKey(model interface{}) bool {
+	keys := 0
+
+	for _, f := range structFields(reflect.TypeOf(model)) {
+		if _, ok := gormSetting(f.Tag, "primary_key"); ok {
+			keys++
+		}
+	}
+
+	return keys > 1
+}
+
+// IsJSON reports whether the type holds a JSON document, e.g. map[string]interface{}, json.RawMessage or postgres.Jsonb.
+func IsJSON(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t.Kind() == reflect.Map || t == reflect.TypeOf(json.RawMessage{}) || t.Name() == "Jsonb"
+}
+
+func isInteger(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+
+	return false
+}
+
 func QueryFields(model interface{}, fields map[string]interface{}) string {
 	var jsonTag, jsonKey string
 
-	ts, vs := reflect.TypeOf(model), reflect.ValueOf(model)
+	ts := reflect.TypeOf(model)
 
 	assocs := make(map[string]AssociationType)
+	foreignKeys := make(map[string]string)
 
-	for i := 0; i < ts.NumField(); i++ {
-		f := ts.Field(i)
+	for _, f := range structFields(ts) {
 		jsonTag = f.Tag.Get("json")
 
 		if jsonTag == "" {
@@ -54,15 +218,26 @@ func QueryFields(model interface{}, fields map[string]interface{}) string {
 			jsonKey = strings.Split(jsonTag, ",")[0]
 		}
 
-		switch vs.Field(i).Kind() {
+		switch f.Type.Kind() {
 		case reflect.Ptr:
-			if _, ok := ts.FieldByName(f.Name + "ID"); ok {
+			if foreignKey, ok := belongsToForeignKey(ts, f); ok {
 				assocs[jsonKey] = belongsTo
+				foreignKeys[jsonKey] = foreignKey
 			} else {
 				assocs[jsonKey] = hasOne
 			}
 		case reflect.Slice:
-			assocs[jsonKey] = hasMany
+			elem := f.Type.Elem()
+
+			for elem.Kind() == reflect.Ptr {
+				elem = elem.Elem()
+			}
+
+			if elem.Kind() == reflect.Struct {
+				assocs[jsonKey] = hasMany
+			} else {
+				assocs[jsonKey] = none
+			}
 		default:
 			assocs[jsonKey] = none
 		}
@@ -83,9 +258,10 @@ func QueryFields(model interface{}, fields map[string]interface{}) string {
 		case none:
 			result = append(result, k)
 		case belongsTo:
-			result = append(result, k+"_id")
+			result = append(result, foreignKeys[k])
 		default:
-			result = append(result, "id")
+			primaryKey, _ := PrimaryKey(model)
+			result = append(result, primaryKey)
 		}
 	}
 
@@ -145,7 +321,7 @@ func FieldToMap(model interface{}, fields map[string]interface{}) (map[string]in
 
 	if !contains(fields, "*") {
 		for field, _ := range fields {
-			if !vs.FieldByName(snaker.SnakeToCamel(field)).IsValid() {
+			if f, ok := ts.FieldByName(snaker.SnakeToCamel(field)); !ok || !Readable(f) {
 				return nil, errors.New("Invalid Parameter. The specified field does not exist.")
 			}
 		}
@@ -154,8 +330,16 @@ func FieldToMap(model interface{}, fields map[string]interface{}) (map[string]in
 	var jsonKey string
 	var omitEmpty bool
 
-	for i := 0; i < ts.NumField(); i++ {
-		field := ts.Field(i)
+	for _, field := range structFields(ts) {
+		if !Readable(field) {
+			continue
+		}
+
+		fv, ok := fieldByIndex(vs, field.Index)
+		if !ok {
+			continue
+		}
+
 		jsonTag := field.Tag.Get("json")
 		omitEmpty = false
 
@@ -171,8 +355,8 @@ func FieldToMap(model interface{}, fields map[string]interface{}) (map[string]in
 		}
 
 		if contains(fields, "*") {
-			if !omitEmpty || !isEmptyValue(vs.Field(i)) {
-				u[jsonKey] = vs.Field(i).Interface()
+			if !omitEmpty || !isEmptyValue(fv) {
+				u[jsonKey] = exposedValue(fv)
 			}
 
 			continue
@@ -181,12 +365,12 @@ func FieldToMap(model interface{}, fields map[string]interface{}) (map[string]in
 		if contains(fields, jsonKey) {
 			v := fields[jsonKey]
 
-			if vs.Field(i).Kind() == reflect.Ptr {
-				if !vs.Field(i).IsNil() {
+			if fv.Kind() == reflect.Ptr {
+				if !fv.IsNil() {
 					if v == nil {
-						u[jsonKey] = vs.Field(i).Elem().Interface()
+						u[jsonKey] = exposedValue(fv.Elem())
 					} else {
-						k, err := FieldToMap(vs.Field(i).Elem().Interface(), v.(map[string]interface{}))
+						k, err := FieldToMap(fv.Elem().Interface(), v.(map[string]interface{}))
 
 						if err != nil {
 							return nil, err
@@ -201,13 +385,13 @@ func FieldToMap(model interface{}, fields map[string]interface{}) (map[string]in
 						return nil, errors.New("Invalid Parameter. The structure is null.")
 					}
 				}
-			} else if vs.Field(i).Kind() == reflect.Slice {
+			} else if fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() != reflect.Uint8 {
 				var fieldMap []interface{}
-				s := reflect.ValueOf(vs.Field(i).Interface())
+				s := reflect.ValueOf(fv.Interface())
 
 				for i := 0; i < s.Len(); i++ {
 					if v == nil {
-						fieldMap = append(fieldMap, s.Index(i).Interface())
+						fieldMap = append(fieldMap, exposedValue(s.Index(i)))
 					} else {
 
 						if s.Index(i).Kind() == reflect.Ptr {
@@ -233,9 +417,9 @@ func FieldToMap(model interface{}, fields map[string]interface{}) (map[string]in
 				u[jsonKey] = fieldMap
 			} else {
 				if v == nil {
-					u[jsonKey] = vs.Field(i).Interface()
+					u[jsonKey] = exposedValue(fv)
 				} else {
-					k, err := FieldToMap(vs.Field(i).Interface(), v.(map[string]interface{}))
+					k, err := FieldToMap(fv.Interface(), v.(map[string]interface{}))
 
 					if err != nil {
 						return nil, err
@@ -34,6 +34,33 @@ type Company struct {
 	User         *User             `json:"user,omitempty" form:"user"`
 }
 
+type Timestamps struct {
+	CreatedAt string `json:"created_at,omitempty" form:"created_at"`
+	UpdatedAt string `json:"updated_at,omitempty" form:"updated_at"`
+}
+
+type Email struct {
+	ID uint `json:"id" form:"id"`
+	Timestamps
+	Address   string `json:"address" form:"address"`
+	UpdatedAt string `json:"modified_at,omitempty" form:"modified_at"`
+	UserID    uint   `json:"user_id" form:"user_id"`
+}
+
+type Article struct {
+	ID uint `json:"id" form:"id"`
+	*Timestamps
+	Title string `json:"title" form:"title"`
+}
+
+type Post struct {
+	ID          uint  `json:"id" form:"id"`
+	AuthorID    uint  `json:"author_id" form:"author_id"`
+	Author      *User `json:"author" form:"author"`
+	EditorRefer uint  `gorm:"column:editor" json:"editor_refer" form:"editor_refer"`
+	Editor      *User `gorm:"foreignkey:EditorRefer" json:"editor" form:"editor"`
+}
+
 func TestQueryFields_Wildcard(t *testing.T) {
 	fields := map[string]interface{}{"*": nil}
 	result := QueryFields(User{}, fields)
@@ -74,6 +101,24 @@ func TestQueryFields_BelongsTo(t *testing.T) {
 	}
 }
 
+func TestQueryFields_ForeignKey(t *testing.T) {
+	fields := map[string]interface{}{"author": nil}
+	result := QueryFields(Post{}, fields)
+	expected := "author_id"
+
+	if result != expected {
+		t.Fatalf("result should be %s. actual: %s", expected, result)
+	}
+
+	fields = map[string]interface{}{"editor": nil}
+	result = QueryFields(Post{}, fields)
+	expected = "editor"
+
+	if result != expected {
+		t.Fatalf("result should be %s. actual: %s", expected, result)
+	}
+}
+
 func TestQueryFields_HasOne(t *testing.T) {
 	fields := map[string]interface{}{"profile": nil}
 	result := QueryFields(User{}, fields)
@@ -94,6 +139,16 @@ func TestQueryFields_HasMany(t *testing.T) {
 	}
 }
 
+func TestQueryFields_Embedded(t *testing.T) {
+	fields := map[string]interface{}{"created_at": nil}
+	result := QueryFields(Email{}, fields)
+	expected := "created_at"
+
+	if result != expected {
+		t.Fatalf("result should be %s. actual: %s", expected, result)
+	}
+}
+
 func TestParseFields_Wildcard(t *testing.T) {
 	fields := "*"
 	result := ParseFields(fields)
@@ -437,3 +492,65 @@ func TestFieldToMap_NestedField(t *testing.T) {
 		}
 	}
 }
+
+func TestFieldToMap_Embedded(t *testing.T) {
+	email := Email{
+		ID: 1,
+		Timestamps: Timestamps{
+			CreatedAt: "2016-01-01",
+			UpdatedAt: "2016-01-02",
+		},
+		Address:   "taro@example.com",
+		UpdatedAt: "2016-01-03",
+	}
+
+	fields := map[string]interface{}{
+		"*": nil,
+	}
+	result, err := FieldToMap(email, fields)
+
+	if err != nil {
+		t.Fatalf("FieldToMap return an error. detail: %#v", err.Error())
+	}
+
+	for _, key := range []string{"id", "created_at", "address", "modified_at"} {
+		if _, ok := result[key]; !ok {
+			t.Fatalf("%s should exist. actual: %#v", key, result)
+		}
+	}
+
+	for _, key := range []string{"Timestamps", "updated_at"} {
+		if _, ok := result[key]; ok {
+			t.Fatalf("%s should not exist. actual: %#v", key, result)
+		}
+	}
+
+	if result["modified_at"] != "2016-01-03" {
+		t.Fatalf("modified_at should be 2016-01-03. actual: %#v", result["modified_at"])
+	}
+}
+
+func TestFieldToMap_EmbeddedNil(t *testing.T) {
+	article := Article{
+		ID:    1,
+		Title: "Hello",
+	}
+
+	fields := map[string]interface{}{
+		"id":         nil,
+		"created_at": nil,
+	}
+	result, err := FieldToMap(article, fields)
+
+	if err != nil {
+		t.Fatalf("FieldToMap return an error. detail: %#v", err.Error())
+	}
+
+	if _, ok := result["id"]; !ok {
+		t.Fatalf("id should exist. actual: %#v", result)
+	}
+
+	if _, ok := result["created_at"]; ok {
+		t.Fatalf("created_at should not exist. actual: %#v", result)
+	}
+}
@@ -0,0 +1,210 @@
+package helper
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/serenize/snaker"
+)
+
+// apigOption reports whether the option is given by apig tag of the field, e.g. `apig:"readonly,sortable"`.
+func apigOption(f reflect.StructField, option string) bool {
+	for _, s := range strings.Split(f.Tag.Get("apig"), ",") {
+		if strings.TrimSpace(s) == option {
+			return true
+		}
+	}
+
+	return false
+}
+
+func jsonKey(f reflect.StructField) string {
+	if jsonTag := f.Tag.Get("json"); jsonTag != "" {
+		return strings.Split(jsonTag, ",")[0]
+	}
+
+	return f.Name
+}
+
+func columnName(f reflect.StructField) string {
+	if column, ok := gormSetting(f.Tag, "column"); ok {
+		return column
+	}
+
+	return snaker.CamelToSnake(f.Name)
+}
+
+// Readable reports whether the field is exposed in responses.
+func Readable(f reflect.StructField) bool {
+	return !apigOption(f, "hidden") && !apigOption(f, "writeonly")
+}
+
+// Writable reports whether the field can be written through requests.
+func Writable(f reflect.StructField) bool {
+	return !apigOption(f, "hidden") && !apigOption(f, "readonly")
+}
+
+// Filterable reports whether records of the model can be filtered by the field with the JSON key or the column name.
+// All readable fields are filterable unless some fields of the model are tagged with apig:"filterable".
+func Filterable(model interface{}, key string) bool {
+	return allowed(reflect.TypeOf(model), key, "filterable")
+}
+
+// Sortable reports whether records of the model can be sorted by the field with the JSON key or the column name.
+// All readable fields are sortable unless some fields of the model are tagged with apig:"sortable".
+func Sortable(model interface{}, key string) bool {
+	return allowed(reflect.TypeOf(model), key, "sortable")
+}
+
+// FieldByKey returns the field of the model with the JSON key or the column name including the ones of embedded
+// structs.
+func FieldByKey(model interface{}, key string) (reflect.StructField, bool) {
+	for _, f := range structFields(reflect.TypeOf(model)) {
+		if jsonKey(f) == key || columnName(f) == key {
+			return f, true
+		}
+	}
+
+	return reflect.StructField{}, false
+}
+
+// ColumnName returns the column of the field of the model with the JSON key or the column name, as the JSON key
+// cannot be used in queries when it differs from the column, e.g. `json:"name" gorm:"column:user_name"`.
+func ColumnName(model interface{}, key string) (string, bool) {
+	f, ok := FieldByKey(model, key)
+	if !ok {
+		return "", false
+	}
+
+	return columnName(f), true
+}
+
+func allowed(t reflect.Type, key, option string) bool {
+	fields := structFields(t)
+	restricted := false
+
+	for _, f := range fields {
+		if apigOption(f, option) {
+			restricted = true
+		}
+	}
+
+	for _, f := range fields {
+		if jsonKey(f) == key || columnName(f) == key {
+			return Readable(f) && (!restricted || apigOption(f, option))
+		}
+	}
+
+	return false
+}
+
+// ValidateRequired returns an error when the fields tagged with apig:"required" are empty.
+func ValidateRequired(model interface{}) error {
+	vs := reflect.ValueOf(model)
+
+	for _, f := range structFields(vs.Type()) {
+		if !apigOption(f, "required") {
+			continue
+		}
+
+		fv, ok := fieldByIndex(vs, f.Index)
+
+		if !ok || reflect.DeepEqual(fv.Interface(), reflect.Zero(fv.Type()).Interface()) {
+			return fmt.Errorf("Invalid Parameter. The field %s is required.", jsonKey(f))
+		}
+	}
+
+	return nil
+}
+
+// ProtectFields restores the fields which cannot be written through requests, i.e. tagged with
+// apig:"readonly" or apig:"hidden", from the original. model must be a pointer to the struct.
+func ProtectFields(model interface{}, original interface{}) {
+	vs, ovs := reflect.ValueOf(model).Elem(), reflect.ValueOf(original)
+
+	for _, f := range structFields(vs.Type()) {
+		if Writable(f) {
+			continue
+		}
+
+		fv, ok := fieldByIndex(vs, f.Index)
+		if !ok || !fv.CanSet() {
+			continue
+		}
+
+		if ofv, ok := fieldByIndex(ovs, f.Index); ok {
+			fv.Set(ofv)
+		} else {
+			fv.Set(reflect.Zero(fv.Type()))
+		}
+	}
+}
+
+// Exposed returns the model to be rendered in responses. Structs which have fields tagged with
+// apig:"hidden" or apig:"writeonly", including associations, are converted into maps without those fields.
+func Exposed(model interface{}) interface{} {
+	return exposedValue(reflect.ValueOf(model))
+}
+
+func exposedValue(v reflect.Value) interface{} {
+	if !hasHiddenFields(v.Type(), make(map[reflect.Type]bool)) {
+		return v.Interface()
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v.Interface()
+		}
+
+		return exposedValue(v.Elem())
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return v.Interface()
+		}
+
+		result := make([]interface{}, v.Len())
+
+		for i := 0; i < v.Len(); i++ {
+			result[i] = exposedValue(v.Index(i))
+		}
+
+		return result
+	case reflect.Struct:
+		fieldMap, err := FieldToMap(v.Interface(), map[string]interface{}{"*": nil})
+		if err != nil {
+			return v.Interface()
+		}
+
+		return fieldMap
+	}
+
+	return v.Interface()
+}
+
+// hasHiddenFields reports whether values of the type have fields which must not be exposed,
+// including the fields of associations.
+func hasHiddenFields(t reflect.Type, visited map[reflect.Type]bool) bool {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct || visited[t] {
+		return false
+	}
+
+	visited[t] = true
+
+	for _, f := range structFields(t) {
+		if f.PkgPath != "" {
+			continue
+		}
+
+		if !Readable(f) || hasHiddenFields(f.Type, visited) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,137 @@
+package helper
+
+import (
+	"testing"
+)
+
+type Account struct {
+	ID           uint      `json:"id" form:"id" apig:"readonly,sortable"`
+	Name         string    `json:"name" form:"name" gorm:"column:account_name" apig:"required,filterable,sortable"`
+	Password     string    `json:"password,omitempty" form:"password" apig:"writeonly"`
+	PasswordHash string    `json:"-" form:"-" apig:"hidden"`
+	Owner        *Account  `json:"owner,omitempty" form:"owner"`
+	Members      []Account `json:"members,omitempty" form:"members"`
+}
+
+func TestFilterable(t *testing.T) {
+	for _, key := range []string{"name"} {
+		if !Filterable(Account{}, key) {
+			t.Fatalf("Account should be filterable by %s.", key)
+		}
+	}
+
+	for _, key := range []string{"id", "password", "password_hash", "unknown"} {
+		if Filterable(Account{}, key) {
+			t.Fatalf("Account should not be filterable by %s.", key)
+		}
+	}
+
+	if !Filterable(User{}, "id") {
+		t.Fatalf("User should be filterable by id.")
+	}
+}
+
+func TestSortable(t *testing.T) {
+	for _, key := range []string{"id", "name"} {
+		if !Sortable(Account{}, key) {
+			t.Fatalf("Account should be sortable by %s.", key)
+		}
+	}
+
+	for _, key := range []string{"password", "password_hash"} {
+		if Sortable(Account{}, key) {
+			t.Fatalf("Account should not be sortable by %s.", key)
+		}
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	for _, key := range []string{"name", "account_name"} {
+		if column, ok := ColumnName(Account{}, key); !ok || column != "account_name" {
+			t.Fatalf("Incorrect column of %s. expected: account_name, actual: %s", key, column)
+		}
+	}
+
+	if _, ok := ColumnName(Account{}, "unknown"); ok {
+		t.Fatalf("Column of the unknown key should not be found.")
+	}
+}
+
+func TestValidateRequired(t *testing.T) {
+	if err := ValidateRequired(Account{}); err == nil {
+		t.Fatalf("Error should be raised when name is empty.")
+	}
+
+	if err := ValidateRequired(Account{Name: "wantedly"}); err != nil {
+		t.Fatalf("Error should not be raised: %s", err)
+	}
+}
+
+func TestProtectFields(t *testing.T) {
+	original := Account{ID: 1, Name: "wantedly", PasswordHash: "hash"}
+	account := Account{ID: 2, Name: "apig", Password: "secret", PasswordHash: "forged"}
+
+	ProtectFields(&account, original)
+
+	if account.ID != 1 || account.PasswordHash != "hash" {
+		t.Fatalf("Read only fields should be restored. actual: %#v", account)
+	}
+
+	if account.Name != "apig" || account.Password != "secret" {
+		t.Fatalf("Writable fields should not be restored. actual: %#v", account)
+	}
+}
+
+func TestFieldToMap_Hidden(t *testing.T) {
+	account := Account{
+		ID:           1,
+		Name:         "wantedly",
+		Password:     "secret",
+		PasswordHash: "hash",
+		Owner:        &Account{ID: 2, Password: "secret"},
+		Members:      []Account{Account{ID: 3, Password: "secret"}},
+	}
+
+	result, err := FieldToMap(account, map[string]interface{}{"*": nil})
+	if err != nil {
+		t.Fatalf("FieldToMap return an error. detail: %#v", err.Error())
+	}
+
+	for _, key := range []string{"password", "-"} {
+		if _, ok := result[key]; ok {
+			t.Fatalf("%s should not exist. actual: %#v", key, result)
+		}
+	}
+
+	owner, ok := result["owner"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("owner should be converted into map. actual: %#v", result["owner"])
+	}
+
+	if _, ok := owner["password"]; ok {
+		t.Fatalf("password of owner should not exist. actual: %#v", owner)
+	}
+
+	members, ok := result["members"].([]interface{})
+	if !ok || len(members) != 1 {
+		t.Fatalf("members should be converted into slice. actual: %#v", result["members"])
+	}
+
+	if _, ok := members[0].(map[string]interface{})["password"]; ok {
+		t.Fatalf("password of members should not exist. actual: %#v", members[0])
+	}
+
+	if _, err := FieldToMap(account, map[string]interface{}{"password": nil}); err == nil {
+		t.Fatalf("Error should be raised when hidden field is specified.")
+	}
+}
+
+func TestExposed(t *testing.T) {
+	if _, ok := Exposed(Account{}).(map[string]interface{}); !ok {
+		t.Fatalf("Account should be converted into map.")
+	}
+
+	if _, ok := Exposed(User{}).(User); !ok {
+		t.Fatalf("User should be returned as it is.")
+	}
+}
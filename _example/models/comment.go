@@ -0,0 +1,8 @@
+package models
+
+type Comment struct {
+	ID        uint   `gorm:"primary_key;AUTO_INCREMENT" json:"id" form:"id"`
+	Body      string `json:"body" form:"body"`
+	OwnerID   uint   `json:"owner_id" form:"owner_id"`
+	OwnerType string `json:"owner_type" form:"owner_type"`
+}
@@ -0,0 +1,7 @@
+package models
+
+type Tag struct {
+	ID    uint    `gorm:"primary_key;AUTO_INCREMENT" json:"id" form:"id"`
+	Name  string  `json:"name" form:"name" apig:"required,filterable,sortable"`
+	Users []*User `gorm:"many2many:user_tags;" json:"users" form:"users"`
+}
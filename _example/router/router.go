@@ -12,6 +12,12 @@ func Initialize(r *gin.Engine) {
 	api := r.Group("api")
 	{
 
+		api.GET("/comments", controllers.GetComments)
+		api.GET("/comments/:id", controllers.GetComment)
+		api.POST("/comments", controllers.CreateComment)
+		api.PUT("/comments/:id", controllers.UpdateComment)
+		api.DELETE("/comments/:id", controllers.DeleteComment)
+
 		api.GET("/companies", controllers.GetCompanies)
 		api.GET("/companies/:id", controllers.GetCompany)
 		api.POST("/companies", controllers.CreateCompany)
@@ -36,11 +42,21 @@ func Initialize(r *gin.Engine) {
 		api.PUT("/profiles/:id", controllers.UpdateProfile)
 		api.DELETE("/profiles/:id", controllers.DeleteProfile)
 
+		api.GET("/tags", controllers.GetTags)
+		api.GET("/tags/:id", controllers.GetTag)
+		api.POST("/tags", controllers.CreateTag)
+		api.PUT("/tags/:id", controllers.UpdateTag)
+		api.DELETE("/tags/:id", controllers.DeleteTag)
+		api.POST("/tags/:id/users/:user_id", controllers.AddTagUser)
+		api.DELETE("/tags/:id/users/:user_id", controllers.RemoveTagUser)
+
 		api.GET("/users", controllers.GetUsers)
 		api.GET("/users/:id", controllers.GetUser)
 		api.POST("/users", controllers.CreateUser)
 		api.PUT("/users/:id", controllers.UpdateUser)
 		api.DELETE("/users/:id", controllers.DeleteUser)
+		api.POST("/users/:id/tags/:tag_id", controllers.AddUserTag)
+		api.DELETE("/users/:id/tags/:tag_id", controllers.RemoveUserTag)
 
 	}
 }
@@ -1,37 +1,147 @@
 package apig
 
-import "strings"
+import (
+	"go/types"
+	"sort"
+	"strings"
+
+	"github.com/gedex/inflector"
+	"github.com/serenize/snaker"
+)
 
 func resolveAssociate(model *Model, modelMap map[string]*Model, parents map[string]bool) {
 	parents[model.Name] = true
+	defer delete(parents, model.Name)
 
 	for i, field := range model.Fields {
 		if field.Association != nil && field.Association.Type != AssociationNone {
 			continue
 		}
 
-		str := strings.Trim(field.Type, "[]*")
-		if modelMap[str] != nil && !parents[str] {
-			resolveAssociate(modelMap[str], modelMap, parents)
+		assocModel := associatedModel(field, modelMap)
+		if assocModel != nil {
+			str := assocModel.Name
+
+			// Self-references and cycles are classified as well, but not resolved recursively again.
+			if !parents[str] {
+				resolveAssociate(modelMap[str], modelMap, parents)
+			}
 
-			var assoc int
-			switch string([]rune(field.Type)[0]) {
-			case "[":
-				if validateForeignKey(modelMap[str].Fields, model.Name) {
+			if polymorphic, ok := field.GormSetting("polymorphic"); ok && validatePolymorphic(modelMap[str].Fields, polymorphic) {
+				assoc := AssociationHasOne
+				if strings.HasPrefix(field.Type, "[") {
 					assoc = AssociationHasMany
-					break
 				}
-				assoc = AssociationBelongsTo
-			default:
-				if validateForeignKey(modelMap[str].Fields, model.Name) {
-					assoc = AssociationHasOne
-					break
+
+				// gorm stores the table name of the owner into the type column by default
+				value, ok := field.GormSetting("polymorphic_value")
+				if !ok {
+					value = inflector.Pluralize(snaker.CamelToSnake(model.Name))
 				}
-				assoc = AssociationBelongsTo
+
+				addPolymorphicValue(modelMap[str].Fields, polymorphic+"Type", value)
+				model.Fields[i].Association = &Association{Type: assoc, Model: modelMap[str], Polymorphic: polymorphic, PolymorphicValue: value}
+				continue
+			}
+
+			if joinTable, ok := field.GormSetting("many2many"); ok && strings.HasPrefix(field.Type, "[") {
+				model.Fields[i].Association = &Association{Type: AssociationManyToMany, Model: modelMap[str], JoinTable: joinTable}
+				continue
 			}
-			model.Fields[i].Association = &Association{Type: assoc, Model: modelMap[str]}
+
+			assoc, foreignKey := associationType(model, field, modelMap[str])
+			model.Fields[i].Association = &Association{Type: assoc, Model: modelMap[str], ForeignKey: foreignKey}
 		} else {
 			model.Fields[i].Association = &Association{Type: AssociationNone}
 		}
 	}
 }
+
+// associatedModel returns the model which the field refers to, e.g. *Profile, []Email or []*billing.Invoice.
+func associatedModel(field *Field, modelMap map[string]*Model) *Model {
+	name := strings.Trim(field.Type, "[]*")
+	qualifier := ""
+
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		qualifier, name = name[:i], name[i+1:]
+	}
+
+	model, ok := modelMap[name]
+	if !ok {
+		return nil
+	}
+
+	// types with the same name in other packages are not models, e.g. time.Time
+	if field.GoType != nil && model.Type != nil {
+		if !types.Identical(namedType(field.GoType), model.Type) {
+			return nil
+		}
+	} else if qualifier != "" && (model.Package == nil || qualifier != model.Package.Name) {
+		return nil
+	}
+
+	return model
+}
+
+func namedType(t types.Type) types.Type {
+	for {
+		switch x := t.(type) {
+		case *types.Pointer:
+			t = x.Elem()
+		case *types.Slice:
+			t = x.Elem()
+		case *types.Array:
+			t = x.Elem()
+		default:
+			return t
+		}
+	}
+}
+
+// associationType infers the association type and its foreign key in the same order as gorm does.
+// The foreign key specified by gorm tag is preferred to the one inferred from the names.
+func associationType(model *Model, field *Field, assocModel *Model) (int, string) {
+	many := strings.HasPrefix(field.Type, "[")
+
+	hasAssoc := AssociationHasOne
+	if many {
+		hasAssoc = AssociationHasMany
+	}
+
+	if foreignKey, ok := field.GormSetting("foreignkey"); ok {
+		if validateField(assocModel.Fields, foreignKey) {
+			return hasAssoc, foreignKey
+		}
+
+		if !many && validateField(model.Fields, foreignKey) {
+			return AssociationBelongsTo, foreignKey
+		}
+	}
+
+	if validateForeignKey(assocModel.Fields, model.Name) {
+		return hasAssoc, model.Name + "ID"
+	}
+
+	if !many && validateForeignKey(model.Fields, field.Name) {
+		return AssociationBelongsTo, field.Name + "ID"
+	}
+
+	return AssociationBelongsTo, ""
+}
+
+func addPolymorphicValue(fields []*Field, name, value string) {
+	for _, field := range fields {
+		if field.Name != name {
+			continue
+		}
+
+		for _, v := range field.PolymorphicValues {
+			if v == value {
+				return
+			}
+		}
+
+		field.PolymorphicValues = append(field.PolymorphicValues, value)
+		sort.Strings(field.PolymorphicValues)
+	}
+}
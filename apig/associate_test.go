@@ -62,3 +62,271 @@ func modelToMap(models ...*Model) map[string]*Model {
 	}
 	return modelMap
 }
+
+func TestResolveAssociateManyToMany(t *testing.T) {
+	user := &Model{
+		Name: "User",
+		Fields: []*Field{
+			&Field{
+				Name: "ID",
+				Type: "uint",
+			},
+			&Field{
+				Name: "Tags",
+				Type: "[]*Tag",
+				Tag:  `gorm:"many2many:user_tags;" json:"tags"`,
+			},
+		},
+	}
+
+	tag := &Model{
+		Name: "Tag",
+		Fields: []*Field{
+			&Field{
+				Name: "ID",
+				Type: "uint",
+			},
+			&Field{
+				Name: "Name",
+				Type: "string",
+			},
+		},
+	}
+
+	resolveAssociate(user, modelToMap(user, tag), make(map[string]bool))
+
+	assoc := user.Fields[1].Association
+	if assoc.Type != AssociationManyToMany {
+		t.Fatalf("Incorrect result. expected: %v, actual: %v", AssociationManyToMany, assoc.Type)
+	}
+
+	if assoc.JoinTable != "user_tags" {
+		t.Fatalf("Incorrect join table. expected: user_tags, actual: %s", assoc.JoinTable)
+	}
+}
+
+func TestResolveAssociatePolymorphic(t *testing.T) {
+	user := &Model{
+		Name: "User",
+		Fields: []*Field{
+			&Field{
+				Name: "ID",
+				Type: "uint",
+			},
+			&Field{
+				Name: "Comments",
+				Type: "[]*Comment",
+				Tag:  `gorm:"polymorphic:Owner;" json:"comments"`,
+			},
+		},
+	}
+
+	company := &Model{
+		Name: "Company",
+		Fields: []*Field{
+			&Field{
+				Name: "ID",
+				Type: "uint",
+			},
+			&Field{
+				Name: "Comment",
+				Type: "*Comment",
+				Tag:  `gorm:"polymorphic:Owner;polymorphic_value:company" json:"comment"`,
+			},
+		},
+	}
+
+	comment := &Model{
+		Name: "Comment",
+		Fields: []*Field{
+			&Field{
+				Name: "ID",
+				Type: "uint",
+			},
+			&Field{
+				Name: "OwnerID",
+				Type: "uint",
+			},
+			&Field{
+				Name: "OwnerType",
+				Type: "string",
+			},
+		},
+	}
+
+	modelMap := modelToMap(user, company, comment)
+	resolveAssociate(user, modelMap, make(map[string]bool))
+	resolveAssociate(company, modelMap, make(map[string]bool))
+
+	assoc := user.Fields[1].Association
+	if assoc.Type != AssociationHasMany || assoc.Polymorphic != "Owner" || assoc.PolymorphicValue != "users" {
+		t.Fatalf("Incorrect result. expected: has many through Owner (users), actual: %#v", assoc)
+	}
+
+	assoc = company.Fields[1].Association
+	if assoc.Type != AssociationHasOne || assoc.PolymorphicValue != "company" {
+		t.Fatalf("Incorrect result. expected: has one through Owner (company), actual: %#v", assoc)
+	}
+
+	values := comment.Fields[2].PolymorphicValues
+	if len(values) != 2 || values[0] != "company" || values[1] != "users" {
+		t.Fatalf("Incorrect polymorphic values. expected: [company users], actual: %v", values)
+	}
+}
+
+func TestResolveAssociateForeignKey(t *testing.T) {
+	post := &Model{
+		Name: "Post",
+		Fields: []*Field{
+			&Field{
+				Name: "ID",
+				Type: "uint",
+			},
+			&Field{
+				Name: "AuthorID",
+				Type: "uint",
+			},
+			&Field{
+				Name: "Author",
+				Type: "*User",
+			},
+			&Field{
+				Name: "EditorRefer",
+				Type: "uint",
+			},
+			&Field{
+				Name: "Editor",
+				Type: "*User",
+				Tag:  `gorm:"foreignkey:EditorRefer" json:"editor"`,
+			},
+		},
+	}
+
+	user := &Model{
+		Name: "User",
+		Fields: []*Field{
+			&Field{
+				Name: "ID",
+				Type: "uint",
+			},
+			&Field{
+				Name: "Posts",
+				Type: "[]*Post",
+				Tag:  `gorm:"foreignkey:AuthorID" json:"posts"`,
+			},
+		},
+	}
+
+	modelMap := modelToMap(post, user)
+	resolveAssociate(post, modelMap, make(map[string]bool))
+	resolveAssociate(user, modelMap, make(map[string]bool))
+
+	cases := []struct {
+		field      *Field
+		assoc      int
+		foreignKey string
+	}{
+		{post.Fields[2], AssociationBelongsTo, "AuthorID"},
+		{post.Fields[4], AssociationBelongsTo, "EditorRefer"},
+		{user.Fields[1], AssociationHasMany, "AuthorID"},
+	}
+
+	for _, c := range cases {
+		assoc := c.field.Association
+		if assoc.Type != c.assoc || assoc.ForeignKey != c.foreignKey {
+			t.Fatalf("Incorrect association of %s. expected: %v (%s), actual: %v (%s)", c.field.Name, c.assoc, c.foreignKey, assoc.Type, assoc.ForeignKey)
+		}
+	}
+}
+
+func TestResolveAssociateSelfReference(t *testing.T) {
+	category := &Model{
+		Name: "Category",
+		Fields: []*Field{
+			&Field{
+				Name: "ID",
+				Type: "uint",
+			},
+			&Field{
+				Name: "ParentID",
+				Type: "*uint",
+			},
+			&Field{
+				Name: "Parent",
+				Type: "*Category",
+			},
+			&Field{
+				Name: "Children",
+				Type: "[]*Category",
+				Tag:  `gorm:"foreignkey:ParentID" json:"children"`,
+			},
+		},
+	}
+
+	resolveAssociate(category, modelToMap(category), make(map[string]bool))
+
+	if assoc := category.Fields[2].Association; assoc.Type != AssociationBelongsTo || assoc.Model != category {
+		t.Fatalf("Incorrect association of Parent. expected: %v, actual: %v", AssociationBelongsTo, assoc.Type)
+	}
+
+	if assoc := category.Fields[3].Association; assoc.Type != AssociationHasMany || assoc.Model != category {
+		t.Fatalf("Incorrect association of Children. expected: %v, actual: %v", AssociationHasMany, assoc.Type)
+	}
+
+	result := category.Fields[2].PreloadAssocs()
+	expect := []string{"Parent", "Parent.Parent", "Parent.Children"}
+
+	if len(result) != len(expect) {
+		t.Fatalf("Incorrect preload associations. expected: %v, actual: %v", expect, result)
+	}
+
+	for i := range expect {
+		if result[i] != expect[i] {
+			t.Fatalf("Incorrect preload associations. expected: %v, actual: %v", expect, result)
+		}
+	}
+}
+
+func TestResolveAssociateCycle(t *testing.T) {
+	user := &Model{
+		Name: "User",
+		Fields: []*Field{
+			&Field{
+				Name: "ID",
+				Type: "uint",
+			},
+			&Field{
+				Name: "Profile",
+				Type: "*Profile",
+			},
+		},
+	}
+
+	profile := &Model{
+		Name: "Profile",
+		Fields: []*Field{
+			&Field{
+				Name: "ID",
+				Type: "uint",
+			},
+			&Field{
+				Name: "UserID",
+				Type: "uint",
+			},
+			&Field{
+				Name: "User",
+				Type: "*User",
+			},
+		},
+	}
+
+	resolveAssociate(user, modelToMap(user, profile), make(map[string]bool))
+
+	if assoc := user.Fields[1].Association; assoc.Type != AssociationHasOne {
+		t.Fatalf("Incorrect association of User.Profile. expected: %v, actual: %v", AssociationHasOne, assoc.Type)
+	}
+
+	if assoc := profile.Fields[2].Association; assoc.Type != AssociationBelongsTo {
+		t.Fatalf("Incorrect association of Profile.User. expected: %v, actual: %v", AssociationBelongsTo, assoc.Type)
+	}
+}
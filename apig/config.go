@@ -0,0 +1,55 @@
+package apig
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+const configFile = "apig.yml"
+
+// Config is the settings of the project declared in apig.yml, which is written by `apig new`.
+type Config struct {
+	// ImportPath is the import path of the project, e.g. github.com/wantedly/api-server
+	ImportPath string `yaml:"import_path"`
+	// Namespace is the path prefix of the API, e.g. api
+	Namespace string `yaml:"namespace"`
+	// Database is the database engine, i.e. sqlite, postgres or mysql
+	Database string `yaml:"database"`
+	// Templates is the directory of the templates overriding the embedded ones, relative to the project
+	Templates string `yaml:"templates"`
+	// Funcs is the functions available in the templates, each of which renders the template with its argument as dot,
+	// e.g. tableName: "{{ .Name | toSnakeCase | pluralize }}"
+	Funcs map[string]string `yaml:"funcs"`
+}
+
+// loadConfig reads apig.yml in outDir. nil is returned when it does not exist, e.g. in projects generated by older apig.
+func loadConfig(outDir string) (*Config, error) {
+	path := filepath.Join(outDir, configFile)
+
+	body, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var config Config
+
+	if err := yaml.Unmarshal(body, &config); err != nil {
+		return nil, fmt.Errorf("Failed to read %s: %s", configFile, err)
+	}
+
+	switch config.Database {
+	case "", "sqlite", "postgres", "mysql":
+	default:
+		return nil, fmt.Errorf("Invalid database in %s: %s. Please specify sqlite, postgres or mysql.", configFile, config.Database)
+	}
+
+	return &config, nil
+}
@@ -0,0 +1,61 @@
+package apig
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	outDir, err := ioutil.TempDir("", "loadConfig")
+	if err != nil {
+		t.Fatal("Failed to create tempdir")
+	}
+	defer os.RemoveAll(outDir)
+
+	if config, err := loadConfig(outDir); config != nil || err != nil {
+		t.Fatalf("Config should be nil when apig.yml does not exist. config: %v, error: %v", config, err)
+	}
+
+	path := filepath.Join(outDir, configFile)
+	body := "import_path: github.com/wantedly/api-server\nnamespace: api\ndatabase: postgres\ntemplates: templates\nfuncs:\n  tableName: \"{{ .Name | toSnakeCase | pluralize }}\"\n"
+
+	if err := ioutil.WriteFile(path, []byte(body), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := loadConfig(outDir)
+	if err != nil {
+		t.Fatalf("Error should not be raised: %s", err)
+	}
+
+	expected := Config{ImportPath: "github.com/wantedly/api-server", Namespace: "api", Database: "postgres", Templates: "templates",
+		Funcs: map[string]string{"tableName": "{{ .Name | toSnakeCase | pluralize }}"}}
+
+	if !reflect.DeepEqual(*config, expected) {
+		t.Fatalf("Incorrect config. expected: %#v, actual: %#v", expected, *config)
+	}
+
+	if err := ioutil.WriteFile(path, []byte("database: oracle\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := loadConfig(outDir); err == nil {
+		t.Fatalf("Error should be raised when the database is not supported.")
+	}
+}
+
+func TestProjectConfig_Legacy(t *testing.T) {
+	config, err := projectConfig(filepath.Join("testdata", "legacy"), "main.go")
+	if err != nil {
+		t.Fatalf("Error should not be raised: %s", err)
+	}
+
+	expected := Config{ImportPath: "github.com/wantedly/api-server", Namespace: "api", Database: "mysql"}
+
+	if !reflect.DeepEqual(*config, expected) {
+		t.Fatalf("Incorrect config. expected: %#v, actual: %#v", expected, *config)
+	}
+}
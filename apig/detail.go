@@ -1,5 +1,11 @@
 package apig
 
+import (
+	"strings"
+	"text/template"
+	"unicode"
+)
+
 type Detail struct {
 	VCS       string
 	User      string
@@ -9,4 +15,82 @@ type Detail struct {
 	Model     *Model
 	ImportDir string
 	Database  string
+
+	manifest *Manifest
+	// templates is the directory of the templates overriding the embedded ones
+	templates string
+	// funcs is the functions available in the templates
+	funcs template.FuncMap
+	// funcDefs is the functions defined in apig.yml, which are a part of the inputs of the generation
+	funcDefs map[string]string
+	// funcsVersion is the version of the functions written in Go given by the caller
+	funcsVersion string
+}
+
+// Group is a group of routes for the models in the same package.
+type Group struct {
+	Dir    string
+	Models Models
+}
+
+// Var returns the variable name of the route group in the router, e.g. api or accountsBilling for models/accounts/billing
+func (g *Group) Var() string {
+	if g.Dir == "" {
+		return "api"
+	}
+
+	words := strings.FieldsFunc(g.Dir, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for i := 1; i < len(words); i++ {
+		words[i] = strings.Title(words[i])
+	}
+
+	name := camelToLowerCamel(strings.Join(words, ""))
+
+	switch name {
+	case "api", "r", "controllers", "gin":
+		return name + "Group"
+	}
+
+	return name
+}
+
+// Packages returns the packages of all models.
+func (d *Detail) Packages() []*Package {
+	return modelPackages(d.Models)
+}
+
+// Groups returns the route groups following the package layout of the models, the one of models itself first.
+// Skipped models are not routed, so packages which have only them are omitted.
+func (d *Detail) Groups() []*Group {
+	groups := []*Group{&Group{}}
+	groupMap := map[string]*Group{"": groups[0]}
+
+	for _, p := range d.Packages() {
+		if _, ok := groupMap[p.Dir]; !ok {
+			groupMap[p.Dir] = &Group{Dir: p.Dir}
+			groups = append(groups, groupMap[p.Dir])
+		}
+	}
+
+	for _, m := range d.Models {
+		if m.Skip {
+			continue
+		}
+
+		g := groupMap[m.Group()]
+		g.Models = append(g.Models, m)
+	}
+
+	result := []*Group{}
+
+	for _, g := range groups {
+		if g.Dir == "" || len(g.Models) > 0 {
+			result = append(result, g)
+		}
+	}
+
+	return result
 }
@@ -0,0 +1,108 @@
+package apig
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+const diffContext = 3
+
+type diffLine struct {
+	kind byte
+	text string
+	// a and b are the indexes of the line in the old and the new content respectively
+	a, b int
+}
+
+// unifiedDiff returns the changes from a to b in the unified format, e.g. to be applied by `git apply`.
+// An empty string is returned when they are the same.
+func unifiedDiff(fromPath, toPath, a, b string) string {
+	al, bl := splitLines(a), splitLines(b)
+	lines := []diffLine{}
+	i, j := 0, 0
+
+	for _, m := range append(matchLines(al, bl), [2]int{len(al), len(bl)}) {
+		for ; i < m[0]; i++ {
+			lines = append(lines, diffLine{'-', al[i], i, j})
+		}
+
+		for ; j < m[1]; j++ {
+			lines = append(lines, diffLine{'+', bl[j], i, j})
+		}
+
+		if i < len(al) && j < len(bl) {
+			lines = append(lines, diffLine{' ', al[i], i, j})
+			i++
+			j++
+		}
+	}
+
+	var buf bytes.Buffer
+
+	for k := 0; k < len(lines); {
+		if lines[k].kind == ' ' {
+			k++
+			continue
+		}
+
+		// changes separated by less than twice the context are put together into a hunk
+		last := k
+		for n := k + 1; n < len(lines) && n <= last+2*diffContext+1; n++ {
+			if lines[n].kind != ' ' {
+				last = n
+			}
+		}
+
+		start, end := k-diffContext, last+diffContext+1
+		if start < 0 {
+			start = 0
+		}
+		if end > len(lines) {
+			end = len(lines)
+		}
+
+		if buf.Len() == 0 {
+			fmt.Fprintf(&buf, "--- %s\n+++ %s\n", fromPath, toPath)
+		}
+
+		writeHunk(&buf, lines[start:end])
+		k = end
+	}
+
+	return buf.String()
+}
+
+func writeHunk(buf *bytes.Buffer, lines []diffLine) {
+	aCount, bCount := 0, 0
+
+	for _, l := range lines {
+		if l.kind != '+' {
+			aCount++
+		}
+
+		if l.kind != '-' {
+			bCount++
+		}
+	}
+
+	// an empty range starts at the line before it
+	aStart, bStart := lines[0].a, lines[0].b
+	if aCount > 0 {
+		aStart++
+	}
+	if bCount > 0 {
+		bStart++
+	}
+
+	fmt.Fprintf(buf, "@@ -%d,%d +%d,%d @@\n", aStart, aCount, bStart, bCount)
+
+	for _, l := range lines {
+		buf.WriteByte(l.kind)
+		buf.WriteString(l.text)
+
+		if !strings.HasSuffix(l.text, "\n") {
+			buf.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package apig
+
+import (
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	a := "package controllers\n\nimport (\n\t\"net/http\"\n)\n\nfunc A() {\n}\n\nfunc B() {\n}\n\nfunc C() {\n}\n\nfunc D() {\n}\n"
+	b := "package controllers\n\nimport (\n\t\"net/http\"\n)\n\nfunc A() {\n\treturn\n}\n\nfunc B() {\n}\n\nfunc C() {\n}\n\nfunc D() {\n}\n\nvar e = 1\n"
+
+	expected := `--- a/controllers/user.go
++++ b/controllers/user.go
+@@ -5,6 +5,7 @@
+ )
+ 
+ func A() {
++	return
+ }
+ 
+ func B() {
+@@ -15,3 +16,5 @@
+ 
+ func D() {
+ }
++
++var e = 1
+`
+
+	if actual := unifiedDiff("a/controllers/user.go", "b/controllers/user.go", a, b); actual != expected {
+		t.Fatalf("Incorrect diff. expected: %q, actual: %q", expected, actual)
+	}
+
+	expected = "--- /dev/null\n+++ b/README.md\n@@ -0,0 +1,2 @@\n+# API\n+\n"
+
+	if actual := unifiedDiff("/dev/null", "b/README.md", "", "# API\n\n"); actual != expected {
+		t.Fatalf("Incorrect diff. expected: %q, actual: %q", expected, actual)
+	}
+
+	if actual := unifiedDiff("a/README.md", "b/README.md", a, a); actual != "" {
+		t.Fatalf("Diff should be empty when the contents are the same. actual: %q", actual)
+	}
+}
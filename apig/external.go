@@ -0,0 +1,196 @@
+package apig
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// pluginProtocolVersion is the version of the JSON exchanged with the plugins, increased on incompatible changes.
+const pluginProtocolVersion = 1
+
+var associationTypes = map[int]string{
+	AssociationNone:       "none",
+	AssociationBelongsTo:  "belongs_to",
+	AssociationHasMany:    "has_many",
+	AssociationHasOne:     "has_one",
+	AssociationManyToMany: "many_to_many",
+}
+
+// pluginRequest is the JSON given to the plugin on stdin.
+type pluginRequest struct {
+	Version   int            `json:"version"`
+	VCS       string         `json:"vcs"`
+	User      string         `json:"user"`
+	Project   string         `json:"project"`
+	ImportDir string         `json:"import_dir"`
+	Namespace string         `json:"namespace"`
+	Database  string         `json:"database"`
+	Models    []*pluginModel `json:"models"`
+}
+
+type pluginModel struct {
+	Name string `json:"name"`
+	// Package is the directory of the package relative to the models directory, which is empty for models itself.
+	Package       string         `json:"package"`
+	QualifiedName string         `json:"qualified_name"`
+	Resource      string         `json:"resource"`
+	Skip          bool           `json:"skip"`
+	ReadOnly      bool           `json:"read_only"`
+	Fields        []*pluginField `json:"fields"`
+}
+
+type pluginField struct {
+	Name              string             `json:"name"`
+	JSONName          string             `json:"json_name"`
+	Type              string             `json:"type"`
+	Tag               string             `json:"tag"`
+	PolymorphicValues []string           `json:"polymorphic_values,omitempty"`
+	Hidden            bool               `json:"hidden"`
+	ReadOnly          bool               `json:"read_only"`
+	WriteOnly         bool               `json:"write_only"`
+	Filterable        bool               `json:"filterable"`
+	Sortable          bool               `json:"sortable"`
+	Required          bool               `json:"required"`
+	Association       *pluginAssociation `json:"association,omitempty"`
+}
+
+type pluginAssociation struct {
+	Type string `json:"type"`
+	// Model is the name of the associated model, which refers to the model in the models of the request.
+	Model            string `json:"model,omitempty"`
+	ForeignKey       string `json:"foreign_key,omitempty"`
+	JoinTable        string `json:"join_table,omitempty"`
+	Polymorphic      string `json:"polymorphic,omitempty"`
+	PolymorphicValue string `json:"polymorphic_value,omitempty"`
+}
+
+// pluginResponse is the JSON returned from the plugin on stdout.
+type pluginResponse struct {
+	Files []*pluginFile `json:"files"`
+	// Error is the message of the error by which the plugin failed to generate the files.
+	Error string `json:"error"`
+}
+
+type pluginFile struct {
+	// Path is the slash-separated path relative to the project.
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+func newPluginRequest(detail *Detail) *pluginRequest {
+	req := &pluginRequest{
+		Version:   pluginProtocolVersion,
+		VCS:       detail.VCS,
+		User:      detail.User,
+		Project:   detail.Project,
+		ImportDir: detail.ImportDir,
+		Namespace: detail.Namespace,
+		Database:  detail.Database,
+		Models:    []*pluginModel{},
+	}
+
+	for _, model := range detail.Models {
+		m := &pluginModel{
+			Name:          model.Name,
+			QualifiedName: model.QualifiedName(),
+			Resource:      model.Resource(),
+			Skip:          model.Skip,
+			ReadOnly:      model.ReadOnly,
+			Fields:        []*pluginField{},
+		}
+
+		if model.Package != nil {
+			m.Package = model.Package.Dir
+		}
+
+		for _, field := range model.Fields {
+			f := &pluginField{
+				Name:              field.Name,
+				JSONName:          field.JSONName,
+				Type:              field.Type,
+				Tag:               field.Tag,
+				PolymorphicValues: field.PolymorphicValues,
+				Hidden:            field.Hidden,
+				ReadOnly:          field.ReadOnly,
+				WriteOnly:         field.WriteOnly,
+				Filterable:        field.Filterable,
+				Sortable:          field.Sortable,
+				Required:          field.Required,
+			}
+
+			if assoc := field.Association; assoc != nil && assoc.Type != AssociationNone {
+				f.Association = &pluginAssociation{
+					Type:             associationTypes[assoc.Type],
+					ForeignKey:       assoc.ForeignKey,
+					JoinTable:        assoc.JoinTable,
+					Polymorphic:      assoc.Polymorphic,
+					PolymorphicValue: assoc.PolymorphicValue,
+				}
+
+				if assoc.Model != nil {
+					f.Association.Model = assoc.Model.Name
+				}
+			}
+
+			m.Fields = append(m.Fields, f)
+		}
+
+		req.Models = append(req.Models, m)
+	}
+
+	return req
+}
+
+type pluginGenerator struct {
+	command string
+}
+
+// PluginGenerator returns the generator running the executable, e.g. apig-gen-foo, as an out-of-process plugin.
+// The plugin reads the models as JSON on stdin and writes the files to be generated as JSON on stdout, by which
+// generators can be written in any language.
+func PluginGenerator(command string) Generator {
+	return &pluginGenerator{command: command}
+}
+
+func (g *pluginGenerator) Generate(detail *Detail, w *FileWriter) error {
+	body, err := json.Marshal(newPluginRequest(detail))
+	if err != nil {
+		return err
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.Command(g.command)
+	cmd.Stdin = bytes.NewReader(body)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%s: %s", err, msg)
+		}
+
+		return err
+	}
+
+	var res pluginResponse
+
+	if err := json.Unmarshal(stdout.Bytes(), &res); err != nil {
+		return fmt.Errorf("Invalid response of the plugin: %s", err)
+	}
+
+	if res.Error != "" {
+		return fmt.Errorf("%s", res.Error)
+	}
+
+	for _, f := range res.Files {
+		if err := w.WriteFile(f.Path, []byte(f.Content)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package apig
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func runTestPlugin() {
+	var req pluginRequest
+
+	if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	res := pluginResponse{}
+
+	if os.Getenv("APIG_TEST_PLUGIN") == "error" {
+		res.Error = "plugin failed"
+	}
+
+	for _, model := range req.Models {
+		var fields []string
+
+		for _, field := range model.Fields {
+			fields = append(fields, field.JSONName)
+		}
+
+		res.Files = append(res.Files, &pluginFile{
+			Path:    "schema/" + model.Resource + ".txt",
+			Content: fmt.Sprintf("%s %s %s\n", req.ImportDir, model.QualifiedName, strings.Join(fields, ",")),
+		})
+	}
+
+	json.NewEncoder(os.Stdout).Encode(res)
+}
+
+func TestPluginGenerator(t *testing.T) {
+	os.Setenv("APIG_TEST_PLUGIN", "1")
+	defer os.Unsetenv("APIG_TEST_PLUGIN")
+
+	opts := &Options{
+		OutDir:     filepath.Join("testdata", "legacy"),
+		DryRun:     true,
+		Generators: map[string]Generator{"schema": PluginGenerator(os.Args[0])},
+	}
+
+	result, err := Generate(opts)
+	if err != nil {
+		t.Fatalf("Error should not be raised: %s", err)
+	}
+
+	var file *File
+
+	for _, f := range result.Files {
+		if f.Path == "schema/users.txt" {
+			file = f
+		}
+	}
+
+	expected := "github.com/wantedly/api-server models.User id,name,created_at,updated_at\n"
+
+	if file == nil || string(file.Content) != expected {
+		t.Fatalf("File of the plugin should be generated. expected: %q, actual: %#v", expected, file)
+	}
+
+	os.Setenv("APIG_TEST_PLUGIN", "error")
+
+	if _, err := Generate(opts); err == nil || !strings.Contains(err.Error(), "plugin failed") {
+		t.Fatalf("Error of the plugin should be raised. actual: %v", err)
+	}
+}
+
+func TestNewPluginRequest(t *testing.T) {
+	company := &Model{Name: "Company", Fields: []*Field{&Field{Name: "ID", JSONName: "id", Type: "uint"}}}
+	user := &Model{
+		Name: "User",
+		Fields: []*Field{
+			&Field{Name: "ID", JSONName: "id", Type: "uint"},
+			&Field{Name: "Company", JSONName: "company", Type: "*Company", Association: &Association{Type: AssociationBelongsTo, Model: company, ForeignKey: "CompanyID"}},
+		},
+	}
+	company.Fields = append(company.Fields, &Field{Name: "Users", JSONName: "users", Type: "[]*User", Association: &Association{Type: AssociationHasMany, Model: user}})
+
+	body, err := json.Marshal(newPluginRequest(&Detail{ImportDir: "github.com/wantedly/api-server", Models: []*Model{company, user}}))
+	if err != nil {
+		t.Fatalf("Models referring to each other should be serialized: %s", err)
+	}
+
+	var req pluginRequest
+
+	if err := json.Unmarshal(body, &req); err != nil {
+		t.Fatalf("Error should not be raised: %s", err)
+	}
+
+	assoc := req.Models[1].Fields[1].Association
+
+	if assoc == nil || assoc.Type != "belongs_to" || assoc.Model != "Company" || assoc.ForeignKey != "CompanyID" {
+		t.Fatalf("Incorrect association. actual: %#v", assoc)
+	}
+
+	if req.Models[0].Resource != "companies" || req.Models[0].Fields[1].Association.Type != "has_many" {
+		t.Fatalf("Incorrect model. actual: %#v", req.Models[0])
+	}
+}
@@ -0,0 +1,222 @@
+package apig
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/gedex/inflector"
+	"github.com/serenize/snaker"
+)
+
+// funcMap is the functions built in the templates. Functions taking the value to be transformed have it as the last
+// argument, so that they can be used in pipelines, e.g. {{ .Name | trimPrefix "Admin" }}
+var funcMap = template.FuncMap{
+	"add":                 add,
+	"apibDefaultValue":    apibDefaultValue,
+	"apibExampleValue":    apibExampleValue,
+	"apibIDValue":         apibIDValue,
+	"apibType":            apibType,
+	"article":             article,
+	"contains":            func(substr, s string) bool { return strings.Contains(s, substr) },
+	"fieldsByAssociation": fieldsByAssociation,
+	"gormSetting":         gormSetting,
+	"hasPrefix":           func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+	"hasSuffix":           func(suffix, s string) bool { return strings.HasSuffix(s, suffix) },
+	"isLast":              isLast,
+	"join":                func(sep string, a []string) string { return strings.Join(a, sep) },
+	"pluralize":           inflector.Pluralize,
+	"primaryKey":          func(m *Model) *Field { return m.PrimaryKey() },
+	"primaryKeys":         func(m *Model) []*Field { return m.PrimaryKeys() },
+	"quote":               strconv.Quote,
+	"replace":             func(old, new, s string) string { return strings.Replace(s, old, new, -1) },
+	"requestParams":       requestParams,
+	"responseFields":      responseFields,
+	"singularize":         inflector.Singularize,
+	"split":               func(sep, s string) []string { return strings.Split(s, sep) },
+	"tag":                 tag,
+	"title":               strings.Title,
+	"toCamelCase":         snaker.SnakeToCamel,
+	"toKebabCase":         toKebabCase,
+	"toLower":             strings.ToLower,
+	"toLowerCamelCase":    camelToLowerCamel,
+	"toOriginalCase":      camelToOriginal,
+	"toSnakeCase":         snaker.CamelToSnake,
+	"toUpper":             strings.ToUpper,
+	"trimPrefix":          func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+	"trimSuffix":          func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+}
+
+var (
+	registeredFuncs = make(template.FuncMap)
+	funcsMu         sync.Mutex
+)
+
+// RegisterFunc registers the function available in all the templates, e.g. in init of the package imported by
+// a custom build of apig. It panics if the name is already built in or registered.
+func RegisterFunc(name string, fn interface{}) {
+	funcsMu.Lock()
+	defer funcsMu.Unlock()
+
+	if _, ok := funcMap[name]; ok {
+		panic("apig: template function " + name + " is built in")
+	}
+
+	if _, ok := registeredFuncs[name]; ok {
+		panic("apig: template function " + name + " is already registered")
+	}
+
+	registeredFuncs[name] = fn
+}
+
+// newFuncMap returns the functions of the templates, i.e. the built-in ones, the registered ones, the given ones
+// and the ones defined in apig.yml as templates.
+func newFuncMap(funcs template.FuncMap, defs map[string]string) (template.FuncMap, error) {
+	result := make(template.FuncMap)
+
+	for name, fn := range funcMap {
+		result[name] = fn
+	}
+
+	funcsMu.Lock()
+	for name, fn := range registeredFuncs {
+		result[name] = fn
+	}
+	funcsMu.Unlock()
+
+	for name, fn := range funcs {
+		if _, ok := result[name]; ok {
+			return nil, fmt.Errorf("template function %s is already defined", name)
+		}
+
+		result[name] = fn
+	}
+
+	if err := validateFuncs(result); err != nil {
+		return nil, err
+	}
+
+	// the functions in apig.yml are parsed with the others so that they can call them, but not each other
+	base := make(template.FuncMap)
+
+	for name, fn := range result {
+		base[name] = fn
+	}
+
+	names := make([]string, 0, len(defs))
+	for name := range defs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if _, ok := result[name]; ok {
+			return nil, fmt.Errorf("template function %s in %s is already defined", name, configFile)
+		}
+
+		tmpl, err := template.New(name).Funcs(base).Parse(defs[name])
+		if err != nil {
+			return nil, fmt.Errorf("Failed to parse template function %s in %s: %s", name, configFile, err)
+		}
+
+		result[name] = templateFunc(tmpl)
+	}
+
+	return result, nil
+}
+
+// validateFuncs returns an error instead of the panic of template.Funcs for the values which are not functions or
+// have invalid signatures, as the panic in the goroutines rendering the files would crash the program.
+func validateFuncs(funcs template.FuncMap) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("invalid template function: %v", r)
+		}
+	}()
+
+	template.New("").Funcs(funcs)
+
+	return nil
+}
+
+// templateFunc returns the function rendering tmpl with the argument as dot.
+func templateFunc(tmpl *template.Template) func(interface{}) (string, error) {
+	return func(v interface{}) (string, error) {
+		var buf bytes.Buffer
+
+		if err := tmpl.Execute(&buf, v); err != nil {
+			return "", err
+		}
+
+		return buf.String(), nil
+	}
+}
+
+func add(a, b int) int {
+	return a + b
+}
+
+// isLast reports whether i is the last index of the slice, e.g. to omit the comma after the last element.
+func isLast(i int, a interface{}) bool {
+	v := reflect.ValueOf(a)
+
+	switch v.Kind() {
+	case reflect.Array, reflect.Slice:
+		return i == v.Len()-1
+	}
+
+	return false
+}
+
+// fieldsByAssociation returns the fields of the association type, i.e. belongs_to, has_many, has_one or
+// many_to_many. none returns the fields which are not associations.
+func fieldsByAssociation(typ string, fields []*Field) ([]*Field, error) {
+	assocType := -1
+
+	for t, name := range associationTypes {
+		if name == typ {
+			assocType = t
+		}
+	}
+
+	if assocType < 0 {
+		return nil, fmt.Errorf("unknown association type %s", typ)
+	}
+
+	result := []*Field{}
+
+	for _, field := range fields {
+		t := AssociationNone
+
+		if field.Association != nil {
+			t = field.Association.Type
+		}
+
+		if t == assocType {
+			result = append(result, field)
+		}
+	}
+
+	return result, nil
+}
+
+// tag returns the value of the key in the struct tag of the field, e.g. {{ tag "json" . }}
+func tag(key string, field *Field) string {
+	return reflect.StructTag(field.Tag).Get(key)
+}
+
+// gormSetting returns the value of the key in gorm struct tag of the field, e.g. {{ gormSetting "column" . }}
+func gormSetting(key string, field *Field) string {
+	value, _ := field.GormSetting(key)
+	return value
+}
+
+// AccountName -> account-name
+func toKebabCase(s string) string {
+	return strings.Replace(snaker.CamelToSnake(s), "_", "-", -1)
+}
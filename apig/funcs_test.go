@@ -0,0 +1,100 @@
+package apig
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func renderTemplate(t *testing.T, funcs template.FuncMap, text string, data interface{}) string {
+	tmpl, err := template.New("test").Funcs(funcs).Parse(text)
+	if err != nil {
+		t.Fatalf("Error should not be raised: %s", err)
+	}
+
+	var buf bytes.Buffer
+
+	if err := tmpl.Execute(&buf, data); err != nil {
+		t.Fatalf("Error should not be raised: %s", err)
+	}
+
+	return buf.String()
+}
+
+func TestFuncMap(t *testing.T) {
+	company := &Model{Name: "Company"}
+	user := &Model{
+		Name: "AdminUser",
+		Fields: []*Field{
+			&Field{Name: "Code", JSONName: "code", Type: "string", Tag: "json:\"code\" gorm:\"primary_key;column:user_code\""},
+			&Field{Name: "Company", JSONName: "company", Type: "*Company", Association: &Association{Type: AssociationBelongsTo, Model: company}},
+			&Field{Name: "CompanyID", JSONName: "company_id", Type: "uint"},
+		},
+	}
+
+	cases := []struct {
+		text     string
+		expected string
+	}{
+		{`{{ .Name | toKebabCase }}`, "admin-user"},
+		{`{{ .Name | trimPrefix "Admin" | singularize }}`, "User"},
+		{`{{ "admin_user" | toCamelCase }}`, "AdminUser"},
+		{`{{ (primaryKey .).Name }} {{ gormSetting "column" (primaryKey .) }} {{ tag "json" (primaryKey .) }}`, "Code user_code code"},
+		{`{{ range fieldsByAssociation "belongs_to" .Fields }}{{ .Name }}{{ end }}`, "Company"},
+		{`{{ $fields := fieldsByAssociation "none" .Fields }}{{ range $i, $f := $fields }}{{ $f.JSONName | quote }}{{ if not (isLast $i $fields) }},{{ end }}{{ end }}`, `"code","company_id"`},
+	}
+
+	for _, c := range cases {
+		if actual := renderTemplate(t, funcMap, c.text, user); actual != c.expected {
+			t.Fatalf("Incorrect result of %s. expected: %q, actual: %q", c.text, c.expected, actual)
+		}
+	}
+}
+
+func TestNewFuncMap(t *testing.T) {
+	funcs, err := newFuncMap(
+		template.FuncMap{"shout": func(s string) string { return strings.ToUpper(s) + "!" }},
+		map[string]string{"tableName": "{{ .Name | toSnakeCase | pluralize }}"},
+	)
+	if err != nil {
+		t.Fatalf("Error should not be raised: %s", err)
+	}
+
+	expected := "admin_users ADMINUSER!"
+
+	if actual := renderTemplate(t, funcs, `{{ tableName . }} {{ shout .Name }}`, &Model{Name: "AdminUser"}); actual != expected {
+		t.Fatalf("Incorrect result. expected: %q, actual: %q", expected, actual)
+	}
+
+	if _, err := newFuncMap(nil, map[string]string{"pluralize": "{{ . }}"}); err == nil {
+		t.Fatalf("Error should be raised when the built-in function is defined again.")
+	}
+
+	if _, err := newFuncMap(nil, map[string]string{"broken": "{{ .Name "}); err == nil {
+		t.Fatalf("Error should be raised when the template of the function is invalid.")
+	}
+
+	for _, fn := range []interface{}{"shout", func() (string, string, error) { return "", "", nil }} {
+		if _, err := newFuncMap(template.FuncMap{"shout": fn}, nil); err == nil {
+			t.Fatalf("Error should be raised when the function is invalid. function: %T", fn)
+		}
+	}
+
+	if _, err := Generate(&Options{OutDir: filepath.Join("testdata", "legacy"), DryRun: true, Funcs: template.FuncMap{"shout": 1}}); err == nil {
+		t.Fatalf("Error should be raised by Generate when the function is invalid.")
+	}
+
+	dir, err := ioutil.TempDir("", "funcs")
+	if err != nil {
+		t.Fatal("Failed to create tempdir")
+	}
+	defer os.RemoveAll(dir)
+
+	if _, err := Skeleton(&SkeletonOptions{OutDir: filepath.Join(dir, "api-server"), Funcs: template.FuncMap{"shout": 1}}); err == nil {
+		t.Fatalf("Error should be raised by Skeleton when the function is invalid.")
+	}
+}
@@ -10,43 +10,54 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
-	"sync"
 	"text/template"
 	"unicode"
 
 	"github.com/gedex/inflector"
 	"github.com/serenize/snaker"
-	"github.com/wantedly/apig/msg"
 	"github.com/wantedly/apig/util"
 )
 
 const (
 	dbDialectPathPrefix = "github.com/jinzhu/gorm/dialects/"
 	templateDir         = "_templates"
+
+	// projectTemplateDir is the directory of the templates overriding the embedded ones in the project.
+	projectTemplateDir = ".apig/templates"
 )
 
-var funcMap = template.FuncMap{
-	"apibDefaultValue": apibDefaultValue,
-	"apibExampleValue": apibExampleValue,
-	"apibType":         apibType,
-	"article":          article,
-	"pluralize":        inflector.Pluralize,
-	"requestParams":    requestParams,
-	"title":            strings.Title,
-	"toLower":          strings.ToLower,
-	"toLowerCamelCase": camelToLowerCamel,
-	"toOriginalCase":   camelToOriginal,
-	"toSnakeCase":      snaker.CamelToSnake,
+// readTemplate returns the template of the name relative to _templates, e.g. "controller.go.tmpl". The template
+// in dir, which has the same layout as _templates, overrides the embedded one.
+func readTemplate(dir, name string) ([]byte, error) {
+	if dir != "" {
+		body, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if err == nil {
+			return body, nil
+		}
+
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+
+	return Asset(filepath.Join(templateDir, name))
 }
 
+const exampleUUID = "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+
 var managedFields = []string{
 	"ID",
 	"CreatedAt",
 	"UpdatedAt",
+	"DeletedAt",
 }
 
 func apibDefaultValue(field *Field) string {
-	switch field.Type {
+	if len(field.PolymorphicValues) > 0 {
+		return field.PolymorphicValues[0]
+	}
+
+	switch field.BasicType() {
 	case "bool", "sql.NullBool":
 		return "false"
 	case "complex64", "complex128", "float32", "float64", "sql.NullFloat64":
@@ -57,11 +68,30 @@ func apibDefaultValue(field *Field) string {
 		return strings.ToUpper(field.Name)
 	case "time.Time", "*time.Time":
 		return "`2000-01-01 00:00:00`"
+	case "uuid.UUID":
+		return "`" + exampleUUID + "`"
+	}
+
+	if field.IsJSON() {
+		return "`{}`"
+	}
+
+	if _, ok := field.ElemType(); ok {
+		return "`[]`"
 	}
 
 	return ""
 }
 
+// apibIDValue returns the example value of the ID in URL parameters.
+func apibIDValue(field *Field) string {
+	if field.IsNumeric() {
+		return "`1`"
+	}
+
+	return "`" + exampleUUID + "`"
+}
+
 func apibExampleValue(s string) string {
 	if s == "" {
 		return ""
@@ -75,10 +105,14 @@ func apibExampleValue(s string) string {
 }
 
 func apibType(field *Field) string {
-	switch field.Type {
+	if len(field.PolymorphicValues) > 0 {
+		return "enum[string]"
+	}
+
+	switch field.BasicType() {
 	case "bool":
 		return "boolean"
-	case "string", "time.Time", "*time.Time":
+	case "string", "time.Time", "*time.Time", "uuid.UUID":
 		return "string"
 	case "complex64", "complex128", "float32", "float64", "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
 		return "number"
@@ -88,20 +122,46 @@ func apibType(field *Field) string {
 		return "number, nullable"
 	case "sql.NullString":
 		return "string, nullable"
+	case "[]byte", "[]uint8":
+		// encoded in base64
+		return "string"
+	}
+
+	if field.IsJSON() {
+		return "object"
+	}
+
+	if elem, ok := field.ElemType(); ok {
+		if t := apibType(elem); t != "" {
+			return fmt.Sprintf("array[%s]", t)
+		}
+
+		return "array"
+	}
+
+	if field.Association == nil {
+		return ""
 	}
 
 	switch field.Association.Type {
 	case AssociationBelongsTo:
-		return strings.ToLower(strings.Replace(field.Type, "*", "", -1))
+		return strings.ToLower(unqualified(strings.Replace(field.Type, "*", "", -1)))
 	case AssociationHasMany:
-		return fmt.Sprintf("array[%s]", strings.ToLower(strings.Trim(field.Type, "[]*")))
+		return fmt.Sprintf("array[%s]", strings.ToLower(unqualified(strings.Trim(field.Type, "[]*"))))
 	case AssociationHasOne:
-		return strings.ToLower(strings.Replace(field.Type, "*", "", -1))
+		return strings.ToLower(unqualified(strings.Replace(field.Type, "*", "", -1)))
+	case AssociationManyToMany:
+		return fmt.Sprintf("array[%s]", strings.ToLower(unqualified(strings.Trim(field.Type, "[]*"))))
 	}
 
 	return ""
 }
 
+// unqualified returns the type name without its package, e.g. billing.Invoice -> Invoice
+func unqualified(s string) string {
+	return s[strings.LastIndex(s, ".")+1:]
+}
+
 func article(s string) string {
 	switch string([]rune(s)[0]) {
 	case "a", "i", "u", "e", "o":
@@ -125,7 +185,7 @@ func requestParams(fields []*Field) []*Field {
 			}
 		}
 
-		if !managed {
+		if !managed && field.IsWritable() {
 			params = append(params, field)
 		}
 	}
@@ -133,6 +193,18 @@ func requestParams(fields []*Field) []*Field {
 	return params
 }
 
+func responseFields(fields []*Field) []*Field {
+	result := []*Field{}
+
+	for _, field := range fields {
+		if field.IsReadable() {
+			result = append(result, field)
+		}
+	}
+
+	return result
+}
+
 // AccountName -> accountName
 func camelToLowerCamel(s string) string {
 	ss := strings.Split(s, "")
@@ -163,86 +235,78 @@ func camelToOriginal(s string) string {
 }
 
 func generateApibIndex(detail *Detail, outDir string) error {
-	body, err := Asset(filepath.Join(templateDir, "index.apib.tmpl"))
+	body, err := readTemplate(detail.templates, "index.apib.tmpl")
 
 	if err != nil {
 		return err
 	}
 
-	tmpl, err := template.New("apib").Funcs(funcMap).Parse(string(body))
+	dstPath := filepath.Join(outDir, "docs", "index.apib")
+	inputs := inputsHash(body, detail, detail.Models)
 
-	if err != nil {
-		return err
+	if detail.manifest.unchanged(dstPath, inputs) {
+		return nil
 	}
 
-	var buf bytes.Buffer
+	tmpl, err := template.New("apib").Funcs(detail.funcs).Parse(string(body))
 
-	if err := tmpl.Execute(&buf, detail); err != nil {
+	if err != nil {
 		return err
 	}
 
-	dstPath := filepath.Join(outDir, "docs", "index.apib")
-
-	if !util.FileExists(filepath.Dir(dstPath)) {
-		if err := util.Mkdir(filepath.Dir(dstPath)); err != nil {
-			return err
-		}
-	}
-
-	if err := ioutil.WriteFile(dstPath, buf.Bytes(), 0644); err != nil {
+	var buf bytes.Buffer
 
+	if err := tmpl.Execute(&buf, detail); err != nil {
 		return err
 	}
 
-	msg.Printf("\t\x1b[32m%s\x1b[0m %s\n", "create", dstPath)
-
-	return nil
+	return writeFile(detail.manifest, dstPath, inputs, buf.Bytes())
 }
 
 func generateApibModel(detail *Detail, outDir string) error {
-	body, err := Asset(filepath.Join(templateDir, "model.apib.tmpl"))
+	body, err := readTemplate(detail.templates, "model.apib.tmpl")
 
 	if err != nil {
 		return err
 	}
 
-	tmpl, err := template.New("apib").Funcs(funcMap).Parse(string(body))
+	dstPath := filepath.Join(outDir, "docs", snaker.CamelToSnake(detail.Model.Name)+".apib")
+	inputs := inputsHash(body, detail, []*Model{detail.Model})
 
-	if err != nil {
-		return err
+	if detail.manifest.unchanged(dstPath, inputs) {
+		return nil
 	}
 
-	var buf bytes.Buffer
+	tmpl, err := template.New("apib").Funcs(detail.funcs).Parse(string(body))
 
-	if err := tmpl.Execute(&buf, detail); err != nil {
+	if err != nil {
 		return err
 	}
 
-	dstPath := filepath.Join(outDir, "docs", snaker.CamelToSnake(detail.Model.Name)+".apib")
-
-	if !util.FileExists(filepath.Dir(dstPath)) {
-		if err := util.Mkdir(filepath.Dir(dstPath)); err != nil {
-			return err
-		}
-	}
+	var buf bytes.Buffer
 
-	if err := ioutil.WriteFile(dstPath, buf.Bytes(), 0644); err != nil {
+	if err := tmpl.Execute(&buf, detail); err != nil {
 		return err
 	}
 
-	msg.Printf("\t\x1b[32m%s\x1b[0m %s\n", "create", dstPath)
-
-	return nil
+	return writeFile(detail.manifest, dstPath, inputs, buf.Bytes())
 }
 
 func generateController(detail *Detail, outDir string) error {
-	body, err := Asset(filepath.Join(templateDir, "controller.go.tmpl"))
+	body, err := readTemplate(detail.templates, "controller.go.tmpl")
 
 	if err != nil {
 		return err
 	}
 
-	tmpl, err := template.New("controller").Funcs(funcMap).Parse(string(body))
+	dstPath := filepath.Join(outDir, "controllers", snaker.CamelToSnake(detail.Model.Name)+".go")
+	inputs := inputsHash(body, detail, []*Model{detail.Model})
+
+	if detail.manifest.unchanged(dstPath, inputs) {
+		return nil
+	}
+
+	tmpl, err := template.New("controller").Funcs(detail.funcs).Parse(string(body))
 
 	if err != nil {
 		return err
@@ -260,31 +324,24 @@ func generateController(detail *Detail, outDir string) error {
 		return err
 	}
 
-	dstPath := filepath.Join(outDir, "controllers", snaker.CamelToSnake(detail.Model.Name)+".go")
-
-	if !util.FileExists(filepath.Dir(dstPath)) {
-		if err := util.Mkdir(filepath.Dir(dstPath)); err != nil {
-			return err
-		}
-	}
-
-	if err := ioutil.WriteFile(dstPath, src, 0644); err != nil {
-		return err
-	}
-
-	msg.Printf("\t\x1b[32m%s\x1b[0m %s\n", "create", dstPath)
-
-	return nil
+	return writeFile(detail.manifest, dstPath, inputs, src)
 }
 
 func generateRootController(detail *Detail, outDir string) error {
-	body, err := Asset(filepath.Join(templateDir, "root_controller.go.tmpl"))
+	body, err := readTemplate(detail.templates, "root_controller.go.tmpl")
 
 	if err != nil {
 		return err
 	}
 
-	tmpl, err := template.New("root_controller").Funcs(funcMap).Parse(string(body))
+	dstPath := filepath.Join(outDir, "controllers", "root.go")
+	inputs := inputsHash(body, detail, detail.Models)
+
+	if detail.manifest.unchanged(dstPath, inputs) {
+		return nil
+	}
+
+	tmpl, err := template.New("root_controller").Funcs(detail.funcs).Parse(string(body))
 
 	if err != nil {
 		return err
@@ -302,31 +359,53 @@ func generateRootController(detail *Detail, outDir string) error {
 		return err
 	}
 
-	dstPath := filepath.Join(outDir, "controllers", "root.go")
+	return writeFile(detail.manifest, dstPath, inputs, src)
+}
 
-	if !util.FileExists(filepath.Dir(dstPath)) {
-		if err := util.Mkdir(filepath.Dir(dstPath)); err != nil {
-			return err
-		}
+func generateREADME(detail *Detail, outDir string) error {
+	body, err := readTemplate(detail.templates, "README.md.tmpl")
+
+	if err != nil {
+		return err
 	}
 
-	if err := ioutil.WriteFile(dstPath, src, 0644); err != nil {
+	dstPath := filepath.Join(outDir, "README.md")
+	inputs := inputsHash(body, detail, detail.Models)
+
+	if detail.manifest.unchanged(dstPath, inputs) {
+		return nil
+	}
+
+	tmpl, err := template.New("readme").Funcs(detail.funcs).Parse(string(body))
+
+	if err != nil {
 		return err
 	}
 
-	msg.Printf("\t\x1b[32m%s\x1b[0m %s\n", "create", dstPath)
+	var buf bytes.Buffer
+
+	if err := tmpl.Execute(&buf, detail); err != nil {
+		return err
+	}
 
-	return nil
+	return writeFile(detail.manifest, dstPath, inputs, buf.Bytes())
 }
 
-func generateREADME(detail *Detail, outDir string) error {
-	body, err := Asset(filepath.Join(templateDir, "README.md.tmpl"))
+func generateRouter(detail *Detail, outDir string) error {
+	body, err := readTemplate(detail.templates, "router.go.tmpl")
 
 	if err != nil {
 		return err
 	}
 
-	tmpl, err := template.New("readme").Funcs(funcMap).Parse(string(body))
+	dstPath := filepath.Join(outDir, "router", "router.go")
+	inputs := inputsHash(body, detail, detail.Models)
+
+	if detail.manifest.unchanged(dstPath, inputs) {
+		return nil
+	}
+
+	tmpl, err := template.New("router").Funcs(detail.funcs).Parse(string(body))
 
 	if err != nil {
 		return err
@@ -338,31 +417,30 @@ func generateREADME(detail *Detail, outDir string) error {
 		return err
 	}
 
-	dstPath := filepath.Join(outDir, "README.md")
-
-	if !util.FileExists(filepath.Dir(dstPath)) {
-		if err := util.Mkdir(filepath.Dir(dstPath)); err != nil {
-			return err
-		}
-	}
+	src, err := format.Source(buf.Bytes())
 
-	if err := ioutil.WriteFile(dstPath, buf.Bytes(), 0644); err != nil {
+	if err != nil {
 		return err
 	}
 
-	msg.Printf("\t\x1b[32m%s\x1b[0m %s\n", "update", dstPath)
-
-	return nil
+	return writeFile(detail.manifest, dstPath, inputs, src)
 }
 
-func generateRouter(detail *Detail, outDir string) error {
-	body, err := Asset(filepath.Join(templateDir, "router.go.tmpl"))
+func generateDB(detail *Detail, outDir string) error {
+	body, err := readTemplate(detail.templates, "db.go.tmpl")
 
 	if err != nil {
 		return err
 	}
 
-	tmpl, err := template.New("router").Funcs(funcMap).Parse(string(body))
+	dstPath := filepath.Join(outDir, "db", "db.go")
+	inputs := inputsHash(body, detail, detail.Models)
+
+	if detail.manifest.unchanged(dstPath, inputs) {
+		return nil
+	}
+
+	tmpl, err := template.New("db").Funcs(detail.funcs).Parse(string(body))
 
 	if err != nil {
 		return err
@@ -380,31 +458,24 @@ func generateRouter(detail *Detail, outDir string) error {
 		return err
 	}
 
-	dstPath := filepath.Join(outDir, "router", "router.go")
+	return writeFile(detail.manifest, dstPath, inputs, src)
+}
 
-	if !util.FileExists(filepath.Dir(dstPath)) {
-		if err := util.Mkdir(filepath.Dir(dstPath)); err != nil {
-			return err
-		}
-	}
+func generateGoMod(detail *Detail, outDir string) error {
+	body, err := readTemplate(detail.templates, "go.mod.tmpl")
 
-	if err := ioutil.WriteFile(dstPath, src, 0644); err != nil {
+	if err != nil {
 		return err
 	}
 
-	msg.Printf("\t\x1b[32m%s\x1b[0m %s\n", "update", dstPath)
-
-	return nil
-}
-
-func generateDB(detail *Detail, outDir string) error {
-	body, err := Asset(filepath.Join(templateDir, "db.go.tmpl"))
+	dstPath := filepath.Join(outDir, "go.mod")
+	inputs := inputsHash(body, detail, nil)
 
-	if err != nil {
-		return err
+	if detail.manifest.unchanged(dstPath, inputs) {
+		return nil
 	}
 
-	tmpl, err := template.New("db").Funcs(funcMap).Parse(string(body))
+	tmpl, err := template.New("gomod").Funcs(detail.funcs).Parse(string(body))
 
 	if err != nil {
 		return err
@@ -416,124 +487,154 @@ func generateDB(detail *Detail, outDir string) error {
 		return err
 	}
 
-	src, err := format.Source(buf.Bytes())
+	return writeFile(detail.manifest, dstPath, inputs, buf.Bytes())
+}
 
-	if err != nil {
-		return err
-	}
+func generateCommonFiles(detail *Detail, outDir string) error {
+	var tasks []func() error
 
-	dstPath := filepath.Join(outDir, "db", "db.go")
+	for _, model := range detail.Models {
+		if model.Skip {
+			continue
+		}
 
-	if !util.FileExists(filepath.Dir(dstPath)) {
-		if err := util.Mkdir(filepath.Dir(dstPath)); err != nil {
-			return err
+		d := &Detail{
+			Model:        model,
+			ImportDir:    detail.ImportDir,
+			VCS:          detail.VCS,
+			User:         detail.User,
+			Project:      detail.Project,
+			manifest:     detail.manifest,
+			templates:    detail.templates,
+			funcs:        detail.funcs,
+			funcDefs:     detail.funcDefs,
+			funcsVersion: detail.funcsVersion,
 		}
-	}
 
-	if err := ioutil.WriteFile(dstPath, src, 0644); err != nil {
-		return err
-	}
+		for _, generate := range []func(*Detail, string) error{generateApibModel, generateController} {
+			generate := generate
+			tasks = append(tasks, func() error {
+				if err := generate(d, outDir); err != nil {
+					return fmt.Errorf("model %s: %s", d.Model.Name, err)
+				}
 
-	msg.Printf("\t\x1b[32m%s\x1b[0m %s\n", "update", dstPath)
+				return nil
+			})
+		}
+	}
 
-	return nil
+	return runTasks(tasks)
 }
 
-func generateCommonFiles(detail *Detail, outDir string) error {
-	var wg sync.WaitGroup
-	errCh := make(chan error, 1)
-	done := make(chan bool, 1)
+// collectModels loads models from the packages in outModelDir and its subdirectories.
+// importPath is the import path of outModelDir, by which the packages import each other.
+// The helper structs skipped because of their problems are reported as warnings.
+func collectModels(outModelDir, importPath string) (Models, []string, error) {
+	dirs := make(map[string][]string)
 
-	for _, model := range detail.Models {
-		wg.Add(1)
-		go func(m *Model) {
-			defer wg.Done()
-			d := &Detail{
-				Model:     m,
-				ImportDir: detail.ImportDir,
-				VCS:       detail.VCS,
-				User:      detail.User,
-				Project:   detail.Project,
-			}
+	err := filepath.Walk(outModelDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
 
-			if err := generateApibModel(d, outDir); err != nil {
-				fmt.Fprintln(os.Stderr, err)
-				errCh <- err
-			}
+		name := info.Name()
 
-			if err := generateController(d, outDir); err != nil {
-				fmt.Fprintln(os.Stderr, err)
-				errCh <- err
+		if info.IsDir() {
+			// the same directories as the go tool are ignored
+			if path != outModelDir && (strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "testdata" || name == "vendor") {
+				return filepath.SkipDir
 			}
-		}(model)
-	}
 
-	wg.Wait()
-	close(done)
+			return nil
+		}
+
+		if !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			return nil
+		}
 
-	select {
-	case <-done:
-	case err := <-errCh:
+		dir, err := filepath.Rel(outModelDir, filepath.Dir(path))
 		if err != nil {
 			return err
 		}
-	}
 
-	return nil
-}
+		if dir == "." {
+			dir = ""
+		}
+
+		dir = filepath.ToSlash(dir)
+		dirs[dir] = append(dirs[dir], path)
 
-func collectModels(outModelDir string) (Models, error) {
-	files, err := ioutil.ReadDir(outModelDir)
+		return nil
+	})
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	var models Models
-	var wg sync.WaitGroup
-	var mu sync.Mutex
-	errCh := make(chan error, 1)
-	done := make(chan bool, 1)
+	// All files of a package are type-checked together so that types declared in other files are resolved.
+	models, warnings, err := parsePackages(dirs, importPath)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	for _, file := range files {
-		wg.Add(1)
-		go func(f os.FileInfo) {
-			defer wg.Done()
-			if f.IsDir() {
-				return
-			}
+	return models, warnings, nil
+}
 
-			if !strings.HasSuffix(f.Name(), ".go") {
-				return
-			}
+// assignAliases names the packages of the models so that they conflict with neither each other
+// nor the identifiers used in the generated code.
+func assignAliases(models Models) {
+	used := map[string]bool{
+		"c": true, "content": true, "controllers": true, "db": true, "dbpkg": true, "err": true, "fieldMap": true,
+		"fields": true, "filepath": true, "fmt": true, "gin": true, "gorm": true, "helper": true, "http": true,
+		"id": true, "index": true, "json": true, "lastID": true, "log": true, "original": true, "os": true,
+		"parameter": true, "queryFields": true, "snaker": true, "strings": true, "ver": true, "version": true,
+	}
 
-			modelPath := filepath.Join(outModelDir, f.Name())
-			ms, err := parseModel(modelPath)
-			if err != nil {
-				errCh <- err
-			}
+	for _, m := range models {
+		used[camelToLowerCamel(m.Name)] = true
+		used[inflector.Pluralize(camelToLowerCamel(m.Name))] = true
+	}
+
+	packages := modelPackages(models)
+
+	// shallower packages keep their names, e.g. models/billing rather than models/accounts/billing
+	sort.SliceStable(packages, func(i, j int) bool {
+		return strings.Count(packages[i].Dir, "/") < strings.Count(packages[j].Dir, "/")
+	})
 
-			mu.Lock()
+	for _, p := range packages {
+		if p.Dir == "" {
+			// models is imported without any alias as it has been
+			used[p.Alias] = true
+			continue
+		}
 
-			for _, m := range ms {
-				models = append(models, m)
+		base := strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				return unicode.ToLower(r)
 			}
 
-			mu.Unlock()
-		}(file)
-	}
+			return -1
+		}, p.Dir)
 
-	wg.Wait()
-	close(done)
+		candidates := []string{p.Name, base, base + "models"}
+		alias := ""
 
-	select {
-	case <-done:
-	case err := <-errCh:
-		if err != nil {
-			return nil, err
+		for _, c := range candidates {
+			if !used[c] {
+				alias = c
+				break
+			}
+		}
+
+		for i := 2; alias == ""; i++ {
+			if c := fmt.Sprintf("%smodels%d", base, i); !used[c] {
+				alias = c
+			}
 		}
-	}
 
-	return models, nil
+		p.Alias = alias
+		used[alias] = true
+	}
 }
 
 func detectDatabase(outDir string) (string, error) {
@@ -571,16 +672,112 @@ func detectImportDir(targetPath string) (string, error) {
 	return importDir[0], nil
 }
 
-func Generate(outDir, modelDir, targetFile string, all bool) int {
-	outModelDir := filepath.Join(outDir, modelDir)
+// projectConfig returns the settings of the project in apig.yml. The settings not declared there are detected from
+// go.mod and the source files, as the projects generated by older apig do not have apig.yml.
+func projectConfig(outDir, targetFile string) (*Config, error) {
+	config, err := loadConfig(outDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if config == nil {
+		namespace, err := parseNamespace(filepath.Join(outDir, "router", "router.go"))
+		if err != nil {
+			return nil, err
+		}
+
+		config = &Config{Namespace: namespace}
+	}
+
+	if config.ImportPath == "" {
+		if config.ImportPath, err = detectModulePath(outDir); err != nil {
+			return nil, err
+		}
+	}
+
+	if config.ImportPath == "" {
+		if config.ImportPath, err = detectImportDir(filepath.Join(outDir, targetFile)); err != nil {
+			return nil, err
+		}
+	}
+
+	if config.Database == "" {
+		if config.Database, err = detectDatabase(outDir); err != nil {
+			return nil, err
+		}
+	}
+
+	return config, nil
+}
+
+// Options is the options of Generate.
+type Options struct {
+	// OutDir is the root directory of the project.
+	OutDir string
+	// ModelDir is the directory of the models relative to OutDir. models is used if it is empty.
+	ModelDir string
+	// TargetFile is the file relative to OutDir by which the import path of the project is detected unless it is
+	// declared in apig.yml or go.mod. main.go is used if it is empty.
+	TargetFile string
+	// Templates is the directory of the templates overriding the embedded ones. The directory specified in
+	// apig.yml or .apig/templates of OutDir is used if it is empty.
+	Templates string
+	// All generates the boilerplate as well.
+	All bool
+	// Force overwrites the files edited by hand instead of merging the edits.
+	Force bool
+	// DryRun renders the files in memory without writing anything. Their contents are in the result.
+	DryRun bool
+	// Generators are run after the built-in generators in addition to the ones registered by Register.
+	Generators map[string]Generator
+	// Funcs are available in the templates in addition to the built-in functions and the ones registered by
+	// RegisterFunc and declared in apig.yml.
+	Funcs template.FuncMap
+	// FuncsVersion is the version of Funcs and the functions registered by RegisterFunc. Only the names of them are
+	// known to the generation otherwise, so change it when they are changed to generate the files again.
+	FuncsVersion string
+}
+
+// Generate generates the files of the models in the project. The edits by hand since the last generation are
+// merged into the generated files, and the files are left as they are when the edits conflict with the changes by
+// the generation, which are reported in the result.
+func Generate(opts *Options) (*Result, error) {
+	outDir, modelDir, targetFile := opts.OutDir, opts.ModelDir, opts.TargetFile
 
-	models, err := collectModels(outModelDir)
+	if modelDir == "" {
+		modelDir = "models"
+	}
+
+	if targetFile == "" {
+		targetFile = "main.go"
+	}
+
+	config, err := projectConfig(outDir, targetFile)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		return 1
+		return nil, err
+	}
+
+	templates := opts.Templates
+
+	if templates == "" && config.Templates != "" {
+		templates = filepath.Join(outDir, config.Templates)
+	}
+
+	if templates == "" {
+		templates = filepath.Join(outDir, projectTemplateDir)
+	} else if !util.FileExists(templates) {
+		return nil, fmt.Errorf("%s is not found", templates)
+	}
+
+	importDir := config.ImportPath
+
+	models, warnings, err := collectModels(filepath.Join(outDir, modelDir), importDir+"/"+modelDir)
+	if err != nil {
+		return nil, err
 	}
 
 	sort.Sort(models)
+	assignAliases(models)
 	modelMap := map[string]*Model{}
 
 	for _, m := range models {
@@ -592,79 +789,87 @@ func Generate(outDir, modelDir, targetFile string, all bool) int {
 		resolveAssociate(model, modelMap, make(map[string]bool))
 	}
 
-	importDir, err := detectImportDir(filepath.Join(outDir, targetFile))
-	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		return 1
-	}
-
-	dirs := strings.SplitN(importDir, "/", 3)
+	result := &Result{Warnings: warnings}
 
-	if len(dirs) < 3 {
-		fmt.Fprintln(os.Stderr, "Invalid import path: "+importDir)
-		return 1
+	for _, model := range models {
+		for _, field := range model.Fields {
+			if err := validateStorage(field); err != nil {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("field %s of model %s: %s", field.Name, model.Name, err))
+			}
+		}
 	}
-	vcs, user, project := dirs[0], dirs[1], dirs[2]
 
-	namespace, err := parseNamespace(filepath.Join(outDir, "router", "router.go"))
+	funcs, err := newFuncMap(opts.Funcs, config.Funcs)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		return 1
+		return nil, err
 	}
 
-	database, err := detectDatabase(outDir)
+	vcs, user, project := splitImportPath(importDir)
+
+	manifest, err := loadManifest(outDir)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		return 1
+		return nil, err
 	}
 
+	manifest.force = opts.Force
+	manifest.dryRun = opts.DryRun
+
 	detail := &Detail{
-		Models:    models,
-		ImportDir: importDir,
-		VCS:       vcs,
-		User:      user,
-		Project:   project,
-		Namespace: namespace,
-		Database:  database,
+		Models:       models,
+		ImportDir:    importDir,
+		VCS:          vcs,
+		User:         user,
+		Project:      project,
+		Namespace:    config.Namespace,
+		Database:     config.Database,
+		manifest:     manifest,
+		templates:    templates,
+		funcs:        funcs,
+		funcDefs:     config.Funcs,
+		funcsVersion: opts.FuncsVersion,
 	}
 
-	if err := generateCommonFiles(detail, outDir); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		return 1
-	}
+	err = generateFiles(detail, outDir, opts)
 
-	if all {
-		if err := generateSkeleton(detail, outDir); err != nil {
-			fmt.Fprintln(os.Stderr, err)
-			return 1
-		}
+	// the files written before the failure are recorded as well, as their bases of the merge are already updated
+	if serr := manifest.save(); err == nil {
+		err = serr
 	}
 
-	if err := generateRootController(detail, outDir); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		return 1
+	if err != nil {
+		return nil, err
 	}
 
-	if err := generateApibIndex(detail, outDir); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		return 1
+	result.Files = manifest.result()
+
+	return result, nil
+}
+
+// generateFiles runs the built-in generators and the plugins.
+func generateFiles(detail *Detail, outDir string, opts *Options) error {
+	if err := generateCommonFiles(detail, outDir); err != nil {
+		return err
 	}
 
-	if err := generateRouter(detail, outDir); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		return 1
+	if opts.All {
+		if err := generateSkeleton(detail, outDir, regeneratedFiles); err != nil {
+			return err
+		}
 	}
 
-	if err := generateDB(detail, outDir); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		return 1
+	generators := []func(*Detail, string) error{
+		generateRootController,
+		generateApibIndex,
+		generateRouter,
+		generateDB,
+		generateREADME,
 	}
 
-	if err := generateREADME(detail, outDir); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		return 1
+	for _, generate := range generators {
+		if err := generate(detail, outDir); err != nil {
+			return err
+		}
 	}
 
-	fmt.Println("===> Generated...")
-	return 0
+	return runGenerators(detail, outDir, opts.Generators)
 }
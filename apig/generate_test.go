@@ -4,7 +4,10 @@ import (
 	"bytes"
 	"io/ioutil"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"sort"
+	"strings"
 	"testing"
 
 	"github.com/wantedly/apig/msg"
@@ -52,6 +55,7 @@ var detail = &Detail{
 	Models:    []*Model{userModel},
 	ImportDir: "github.com/wantedly/api-server",
 	Namespace: "",
+	funcs:     funcMap,
 }
 
 func compareFiles(f1, f2 string) bool {
@@ -70,6 +74,12 @@ func teardown() {
 }
 
 func TestMain(m *testing.M) {
+	// the test binary runs as the plugin of TestPluginGenerator
+	if os.Getenv("APIG_TEST_PLUGIN") != "" {
+		runTestPlugin()
+		os.Exit(0)
+	}
+
 	setup()
 	code := m.Run()
 	teardown()
@@ -154,6 +164,46 @@ func TestGenerateController(t *testing.T) {
 	}
 }
 
+func TestGenerateController_Template(t *testing.T) {
+	outDir, err := ioutil.TempDir("", "generateController")
+	if err != nil {
+		t.Fatal("Failed to create tempdir")
+	}
+	defer os.RemoveAll(outDir)
+
+	templates := filepath.Join(outDir, projectTemplateDir)
+
+	if err := os.MkdirAll(templates, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(templates, "controller.go.tmpl"), []byte("package controllers\n\n// {{ .Model.Name }} controller\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	d := *detail
+	d.templates = templates
+
+	if err := generateController(&d, outDir); err != nil {
+		t.Fatalf("Error should not be raised: %s", err)
+	}
+
+	expected := "package controllers\n\n// User controller\n"
+
+	if content, _ := ioutil.ReadFile(filepath.Join(outDir, "controllers", "user.go")); string(content) != expected {
+		t.Fatalf("Controller should be generated from the template in the project. expected: %q, actual: %q", expected, content)
+	}
+
+	body, err := readTemplate(templates, "db.go.tmpl")
+	if err != nil {
+		t.Fatalf("Error should not be raised: %s", err)
+	}
+
+	if asset, _ := Asset(filepath.Join(templateDir, "db.go.tmpl")); !bytes.Equal(body, asset) {
+		t.Fatalf("Embedded template should be used when it is not overridden.")
+	}
+}
+
 func TestGenerateRootController(t *testing.T) {
 	outDir, err := ioutil.TempDir("", "generateRootController")
 	if err != nil {
@@ -315,3 +365,320 @@ func TestGenerateDBMysql(t *testing.T) {
 		t.Fatalf("Failed to generate db.go correctly.\nexpected:\n%s\nactual:\n%s", string(c1), string(c2))
 	}
 }
+
+func TestGenerate_DryRun(t *testing.T) {
+	outDir := filepath.Join("testdata", "legacy")
+
+	result, err := Generate(&Options{OutDir: outDir, DryRun: true})
+	if err != nil {
+		t.Fatalf("Error should not be raised: %s", err)
+	}
+
+	var controller *File
+
+	for _, f := range result.Files {
+		if f.Path == "controllers/user.go" {
+			controller = f
+		}
+	}
+
+	if controller == nil || controller.Status != StatusCreate {
+		t.Fatalf("Controller should be created. actual: %#v", controller)
+	}
+
+	if !bytes.Contains(controller.Content, []byte("func GetUsers(c *gin.Context)")) {
+		t.Fatalf("Controller should be rendered in memory. actual:\n%s", controller.Content)
+	}
+
+	for _, path := range []string{"controllers", "docs", manifestFile} {
+		if _, err := os.Stat(filepath.Join(outDir, path)); !os.IsNotExist(err) {
+			t.Fatalf("%s should not be written in the dry run.", path)
+		}
+	}
+}
+
+func TestGenerate_All(t *testing.T) {
+	dir, err := ioutil.TempDir("", "all")
+	if err != nil {
+		t.Fatal("Failed to create tempdir")
+	}
+	defer os.RemoveAll(dir)
+
+	outDir := filepath.Join(dir, "api-server")
+
+	if _, err := Skeleton(&SkeletonOptions{OutDir: outDir, ImportPath: "github.com/wantedly/api-server", Namespace: "api", Database: "sqlite"}); err != nil {
+		t.Fatalf("Error should not be raised: %s", err)
+	}
+
+	if err := copyDir(filepath.Join("testdata", "legacy", "models"), filepath.Join(outDir, "models")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Generate(&Options{OutDir: outDir, All: true}); err != nil {
+		t.Fatalf("Error should not be raised: %s", err)
+	}
+
+	result, err := Generate(&Options{OutDir: outDir, All: true, DryRun: true})
+	if err != nil {
+		t.Fatalf("Error should not be raised: %s", err)
+	}
+
+	paths := make(map[string]bool)
+
+	for _, f := range result.Files {
+		if paths[f.Path] {
+			t.Fatalf("File should be generated once. actual: %s", f.Path)
+		}
+
+		paths[f.Path] = true
+	}
+
+	if changed := result.Changed(); len(changed) != 0 {
+		t.Fatalf("Files should be up to date. actual: %v", changed)
+	}
+}
+
+func TestApibIDValue(t *testing.T) {
+	if result := apibIDValue(userModel.PrimaryKey()); result != "`1`" {
+		t.Fatalf("Incorrect ID value. expected: `1`, actual: %s", result)
+	}
+
+	device := &Model{
+		Name: "Device",
+		Fields: []*Field{
+			&Field{
+				Name:     "Serial",
+				JSONName: "serial",
+				Type:     "string",
+				Tag:      `gorm:"primary_key;column:serial_number"`,
+			},
+		},
+	}
+
+	if pk := device.PrimaryKey(); pk.Name != "Serial" || pk.Column() != "serial_number" {
+		t.Fatalf("Incorrect primary key. expected: serial_number, actual: %s", pk.Column())
+	}
+
+	if result := apibIDValue(device.PrimaryKey()); result != "`"+exampleUUID+"`" {
+		t.Fatalf("Incorrect ID value. expected: `%s`, actual: %s", exampleUUID, result)
+	}
+}
+
+func TestCompositeKey(t *testing.T) {
+	membership := &Model{
+		Name: "Membership",
+		Fields: []*Field{
+			&Field{
+				Name: "UserID",
+				Type: "uint",
+				Tag:  `gorm:"primary_key"`,
+			},
+			&Field{
+				Name: "CompanyID",
+				Type: "uint",
+				Tag:  `gorm:"primary_key"`,
+			},
+			&Field{
+				Name: "Role",
+				Type: "string",
+			},
+		},
+	}
+
+	if !membership.IsCompositeKey() || userModel.IsCompositeKey() {
+		t.Fatalf("Only Membership should have composite primary key.")
+	}
+
+	params := membership.IDParams()
+
+	if len(params) != 2 || params[0] != "user_id" || params[1] != "company_id" {
+		t.Fatalf("Incorrect ID params. expected: [user_id company_id], actual: %v", params)
+	}
+
+	if result := membership.KeyCondition(); result != "user_id = ? AND company_id = ?" {
+		t.Fatalf("Incorrect key condition. expected: user_id = ? AND company_id = ?, actual: %s", result)
+	}
+
+	if result := membership.Fields[1].VarName(); result != "companyID" {
+		t.Fatalf("Incorrect variable name. expected: companyID, actual: %s", result)
+	}
+
+	if params := userModel.IDParams(); len(params) != 1 || params[0] != "id" {
+		t.Fatalf("Incorrect ID params. expected: [id], actual: %v", params)
+	}
+}
+
+func TestApibType(t *testing.T) {
+	cases := []struct {
+		field    *Field
+		expected string
+	}{
+		{&Field{Name: "Metadata", Type: "map[string]interface{}"}, "object"},
+		{&Field{Name: "Raw", Type: "json.RawMessage"}, "object"},
+		{&Field{Name: "Labels", Type: "[]string"}, "array[string]"},
+		{&Field{Name: "Avatar", Type: "[]byte"}, "string"},
+	}
+
+	for _, c := range cases {
+		if result := apibType(c.field); result != c.expected {
+			t.Fatalf("Incorrect type of %s. expected: %s, actual: %s", c.field.Name, c.expected, result)
+		}
+	}
+}
+
+func TestCollectModels(t *testing.T) {
+	models, _, err := collectModels(filepath.Join("testdata", "packages"), "github.com/wantedly/api-server/models")
+	if err != nil {
+		t.Fatalf("Failed to collect models. error: %s", err)
+	}
+
+	sort.Sort(models)
+	assignAliases(models)
+
+	cases := []struct {
+		name     string
+		dir      string
+		alias    string
+		resource string
+	}{
+		{"Invoice", "billing", "billing", "billing/invoices"},
+		{"Plan", "accounts/billing", "accountsbilling", "accounts/billing/plans"},
+		{"User", "", "models", "users"},
+	}
+
+	if len(models) != len(cases) {
+		t.Fatalf("Number of models is incorrect. expected: %d, actual: %d", len(cases), len(models))
+	}
+
+	modelMap := make(map[string]*Model)
+
+	for i, c := range cases {
+		m := models[i]
+		modelMap[m.Name] = m
+
+		if m.Name != c.name || m.Package.Dir != c.dir || m.Package.Alias != c.alias || m.Resource() != c.resource {
+			t.Fatalf("Incorrect model. expected: %v, actual: {%s %s %s %s}", c, m.Name, m.Package.Dir, m.Package.Alias, m.Resource())
+		}
+	}
+
+	for _, m := range models {
+		resolveAssociate(m, modelMap, make(map[string]bool))
+	}
+
+	if assoc := modelMap["Invoice"].Fields[3].Association; assoc.Type != AssociationBelongsTo || assoc.Model != modelMap["User"] {
+		t.Fatalf("Invoice should belong to User. actual: %#v", assoc)
+	}
+
+	if assoc := modelMap["Plan"].Fields[2].Association; assoc.Type != AssociationManyToMany || assoc.Model != modelMap["Invoice"] {
+		t.Fatalf("Plan should have many Invoices. actual: %#v", assoc)
+	}
+
+	if result := modelMap["Plan"].Fields[2].Association.Model.QualifiedName(); result != "billing.Invoice" {
+		t.Fatalf("Incorrect qualified name. expected: billing.Invoice, actual: %s", result)
+	}
+
+	d := &Detail{Models: models}
+	groups := d.Groups()
+
+	if len(groups) != 3 || groups[0].Var() != "api" || groups[1].Var() != "accountsBilling" || groups[2].Var() != "billing" {
+		t.Fatalf("Incorrect route groups. actual: %#v", groups)
+	}
+}
+
+func TestCollectModelsDuplicated(t *testing.T) {
+	dirs := map[string][]string{
+		"":         []string{filepath.Join("testdata", "packages", "user.go")},
+		"accounts": []string{filepath.Join("testdata", "packages", "user.go")},
+	}
+
+	_, _, err := parsePackages(dirs, "github.com/wantedly/api-server/models")
+	if err == nil || !strings.Contains(err.Error(), "model User is already declared in models") {
+		t.Fatalf("Error should be raised when models share the name. actual: %v", err)
+	}
+}
+
+func copyDir(src, dst string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			return os.MkdirAll(filepath.Join(dst, rel), 0755)
+		}
+
+		body, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		return ioutil.WriteFile(filepath.Join(dst, rel), body, 0644)
+	})
+}
+
+// generateProject generates the project from the models in modelDir and builds it by the go tool. It is skipped when
+// the dependencies of the generated project cannot be fetched, e.g. offline.
+func generateProject(t *testing.T, modelDir string) {
+	if testing.Short() {
+		t.Skip("Building the generated project is skipped in short mode.")
+	}
+
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go is not found.")
+	}
+
+	dir, err := ioutil.TempDir("", "project")
+	if err != nil {
+		t.Fatal("Failed to create tempdir")
+	}
+	defer os.RemoveAll(dir)
+
+	outDir := filepath.Join(dir, "api-server")
+
+	if _, err := Skeleton(&SkeletonOptions{OutDir: outDir, ImportPath: "github.com/wantedly/api-server", Namespace: "api", Database: "sqlite"}); err != nil {
+		t.Fatalf("Error should not be raised: %s", err)
+	}
+
+	if err := copyDir(modelDir, filepath.Join(outDir, "models")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Generate(&Options{OutDir: outDir}); err != nil {
+		t.Fatalf("Error should not be raised: %s", err)
+	}
+
+	get := exec.Command("go", "get", "./...")
+	get.Dir = outDir
+
+	if out, err := get.CombinedOutput(); err != nil {
+		t.Skipf("Dependencies of the generated project are not available: %s\n%s", err, out)
+	}
+
+	build := exec.Command("go", "build", "./...")
+	build.Dir = outDir
+
+	if out, err := build.CombinedOutput(); err != nil {
+		t.Fatalf("Generated project should be built: %s\n%s", err, out)
+	}
+}
+
+func TestGenerate_BuildEmbedded(t *testing.T) {
+	generateProject(t, filepath.Join("testdata", "embedded"))
+}
+
+func TestGenerate_BuildReadOnly(t *testing.T) {
+	generateProject(t, filepath.Join("testdata", "readonly"))
+}
+
+func TestGenerate_BuildCompositeKey(t *testing.T) {
+	generateProject(t, filepath.Join("testdata", "composite"))
+}
+
+func TestGenerate_BuildManyToMany(t *testing.T) {
+	generateProject(t, filepath.Join("testdata", "many2many"))
+}
@@ -1,6 +1,12 @@
 package apig
 
-import "path/filepath"
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
 
 func formatImportDir(paths []string) []string {
 	results := make([]string, 0, len(paths))
@@ -14,3 +20,66 @@ func formatImportDir(paths []string) []string {
 	}
 	return results
 }
+
+// detectModulePath returns the module path declared in go.mod of outDir. An empty string is returned when the
+// project is not a module, e.g. the one in $GOPATH.
+func detectModulePath(outDir string) (string, error) {
+	f, err := os.Open(filepath.Join(outDir, "go.mod"))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+
+	for s.Scan() {
+		line := s.Text()
+
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+
+		fields := strings.Fields(line)
+
+		if len(fields) != 2 || fields[0] != "module" {
+			continue
+		}
+
+		if modulePath, err := strconv.Unquote(fields[1]); err == nil {
+			return modulePath, nil
+		}
+
+		return fields[1], nil
+	}
+
+	return "", s.Err()
+}
+
+// splitImportPath splits the import path of the project into the host, the user and the project, e.g.
+// gitlab.com, wantedly and platform/api-server for gitlab.com/wantedly/platform/api-server. The user of the vanity
+// import path such as go.wantedly.com/api-server is the second-level domain, i.e. wantedly.
+func splitImportPath(importPath string) (vcs, user, project string) {
+	dirs := strings.SplitN(importPath, "/", 3)
+
+	if len(dirs) == 1 {
+		return "", importPath, importPath
+	}
+
+	vcs, project = dirs[0], dirs[len(dirs)-1]
+
+	if len(dirs) == 3 {
+		return vcs, dirs[1], project
+	}
+
+	labels := strings.Split(vcs, ".")
+
+	if len(labels) > 1 {
+		return vcs, labels[len(labels)-2], project
+	}
+
+	return vcs, vcs, project
+}
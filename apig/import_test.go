@@ -1,6 +1,11 @@
 package apig
 
-import "testing"
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
 
 func TestFormatImportDir(t *testing.T) {
 	importPaths := generateImportSlice(
@@ -21,6 +26,54 @@ func TestFormatImportDir(t *testing.T) {
 	}
 }
 
+func TestDetectModulePath(t *testing.T) {
+	outDir, err := ioutil.TempDir("", "detectModulePath")
+	if err != nil {
+		t.Fatal("Failed to create tempdir")
+	}
+	defer os.RemoveAll(outDir)
+
+	if modulePath, err := detectModulePath(outDir); modulePath != "" || err != nil {
+		t.Fatalf("Module path should be empty without go.mod. actual: %s, error: %v", modulePath, err)
+	}
+
+	body := "// API server\nmodule \"gitlab.com/wantedly/platform/api-server\" // deep path\n\ngo 1.13\n"
+
+	if err := ioutil.WriteFile(filepath.Join(outDir, "go.mod"), []byte(body), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	modulePath, err := detectModulePath(outDir)
+	if err != nil {
+		t.Fatalf("Error should not be raised: %s", err)
+	}
+
+	expect := "gitlab.com/wantedly/platform/api-server"
+	if modulePath != expect {
+		t.Fatalf("Incorrect module path. expected: %s, actual: %s", expect, modulePath)
+	}
+}
+
+func TestSplitImportPath(t *testing.T) {
+	testcases := []struct {
+		importPath string
+		expected   [3]string
+	}{
+		{"github.com/wantedly/api-server", [3]string{"github.com", "wantedly", "api-server"}},
+		{"gitlab.com/wantedly/platform/api-server", [3]string{"gitlab.com", "wantedly", "platform/api-server"}},
+		{"go.wantedly.com/api-server", [3]string{"go.wantedly.com", "wantedly", "api-server"}},
+		{"api-server", [3]string{"", "api-server", "api-server"}},
+	}
+
+	for _, tc := range testcases {
+		vcs, user, project := splitImportPath(tc.importPath)
+
+		if actual := [3]string{vcs, user, project}; actual != tc.expected {
+			t.Fatalf("Incorrect split of %s. expected: %v, actual: %v", tc.importPath, tc.expected, actual)
+		}
+	}
+}
+
 func generateImportSlice(paths ...string) []string {
 	var importPaths []string
 	for _, path := range paths {
@@ -0,0 +1,429 @@
+package apig
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"go/types"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/wantedly/apig/util"
+)
+
+const (
+	manifestFile = ".apig/manifest.json"
+
+	// generatedDir keeps the contents of the last generation, which are the base to merge the edits by hand.
+	generatedDir = ".apig/generated"
+
+	// builtinGenerator is the name of the built-in generators in the errors of the files written by two generators.
+	builtinGenerator = "apig"
+)
+
+// Version is the version of apig, which is a part of the inputs of the generation so that the files are generated
+// again by a new apig. It is set by the apig command.
+var Version string
+
+var (
+	assetsOnce sync.Once
+	assetsSum  string
+)
+
+// Manifest records the hashes of the inputs and the outputs of the last generation, by which unchanged files
+// are not generated again and files edited by hand since then are detected.
+type Manifest struct {
+	Files map[string]*ManifestEntry `json:"files"`
+
+	outDir string
+	// force overwrites files edited by hand and dryRun renders files without writing them.
+	force  bool
+	dryRun bool
+	// files are the files reported by the generation.
+	files []*File
+	// owners are the generators of the files, by which a file written by two generators is detected.
+	owners map[string]string
+	mu     sync.Mutex
+}
+
+type ManifestEntry struct {
+	// Inputs is the hash of the template and the models which the file is generated from.
+	Inputs string `json:"inputs"`
+	// Output is the hash of the generated content.
+	Output string `json:"output"`
+}
+
+// loadManifest reads the manifest in outDir. An empty manifest is returned when it does not exist yet.
+func loadManifest(outDir string) (*Manifest, error) {
+	m := &Manifest{
+		Files:  make(map[string]*ManifestEntry),
+		outDir: outDir,
+		owners: make(map[string]string),
+	}
+
+	body, err := ioutil.ReadFile(filepath.Join(outDir, manifestFile))
+	if os.IsNotExist(err) {
+		return m, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(body, m); err != nil {
+		return nil, fmt.Errorf("Failed to read %s: %s", manifestFile, err)
+	}
+
+	if m.Files == nil {
+		m.Files = make(map[string]*ManifestEntry)
+	}
+
+	return m, nil
+}
+
+func (m *Manifest) save() error {
+	if m.dryRun {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	body, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(m.outDir, manifestFile)
+
+	if !util.FileExists(filepath.Dir(path)) {
+		if err := util.Mkdir(filepath.Dir(path)); err != nil {
+			return err
+		}
+	}
+
+	return ioutil.WriteFile(path, append(body, '\n'), 0644)
+}
+
+func (m *Manifest) entry(dstPath string) (string, *ManifestEntry) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key, err := filepath.Rel(m.outDir, dstPath)
+	if err != nil {
+		key = dstPath
+	}
+
+	key = filepath.ToSlash(key)
+
+	return key, m.Files[key]
+}
+
+// record stores the generated content as the base of the next generation.
+func (m *Manifest) record(dstPath, inputs string, src []byte) error {
+	if m == nil || m.dryRun {
+		return nil
+	}
+
+	key, _ := m.entry(dstPath)
+	path := filepath.Join(m.outDir, generatedDir, filepath.FromSlash(key))
+
+	if !util.FileExists(filepath.Dir(path)) {
+		if err := util.Mkdir(filepath.Dir(path)); err != nil {
+			return err
+		}
+	}
+
+	if err := ioutil.WriteFile(path, src, 0644); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.Files[key] = &ManifestEntry{Inputs: inputs, Output: hash(src)}
+
+	return nil
+}
+
+// generated returns the content of the last generation recorded in the manifest.
+func (m *Manifest) generated(dstPath string) ([]byte, bool) {
+	key, e := m.entry(dstPath)
+	if e == nil {
+		return nil, false
+	}
+
+	src, err := ioutil.ReadFile(filepath.Join(m.outDir, generatedDir, filepath.FromSlash(key)))
+	if err != nil || hash(src) != e.Output {
+		return nil, false
+	}
+
+	return src, true
+}
+
+// add reports the file to the result of the generation.
+func (m *Manifest) add(dstPath string, file *File) {
+	if m == nil {
+		return
+	}
+
+	file.Path, _ = m.entry(dstPath)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.files = append(m.files, file)
+
+	// the files of the generators are claimed before they are written
+	if _, ok := m.owners[file.Path]; !ok {
+		m.owners[file.Path] = builtinGenerator
+	}
+}
+
+// claim records the generator writing the file. It returns an error if the file is written by another generator,
+// as the file would be overwritten by whichever runs later.
+func (m *Manifest) claim(dstPath, generator string) error {
+	if m == nil {
+		return nil
+	}
+
+	key, _ := m.entry(dstPath)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if owner, ok := m.owners[key]; ok && owner != generator {
+		return fmt.Errorf("%s is written by both generators %s and %s", key, owner, generator)
+	}
+
+	m.owners[key] = generator
+
+	return nil
+}
+
+// result returns the files reported by the generation.
+func (m *Manifest) result() []*File {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	files := append([]*File(nil), m.files...)
+	sortFiles(files)
+
+	return files
+}
+
+// unchanged reports whether the file need not be generated again as its inputs are not changed since the last
+// generation. The edits by hand are kept in that case.
+func (m *Manifest) unchanged(dstPath, inputs string) bool {
+	if m == nil {
+		return false
+	}
+
+	_, e := m.entry(dstPath)
+	if e == nil || e.Inputs != inputs {
+		return false
+	}
+
+	current, err := ioutil.ReadFile(dstPath)
+	if err != nil {
+		return false
+	}
+
+	if hash(current) == e.Output {
+		// written again to keep the base of the merge when it is lost
+		if _, ok := m.generated(dstPath); !ok {
+			return false
+		}
+
+		m.add(dstPath, &File{Status: StatusIdentical, Content: current, Previous: current})
+		return true
+	}
+
+	if m.force {
+		return false
+	}
+
+	m.add(dstPath, &File{Status: StatusKeep, Reason: "edited since the last generation", Content: current, Previous: current})
+
+	return true
+}
+
+// writeFile writes the generated file unless it is identical to the one on disk. The edits by hand since
+// the last generation are merged into the generated file, and the files are left as they are when the edits
+// conflict with the changes by the generation. Nothing is written in the dry run.
+func writeFile(manifest *Manifest, dstPath, inputs string, src []byte) error {
+	file := &File{Status: StatusCreate, Content: src}
+
+	current, err := ioutil.ReadFile(dstPath)
+	exists := err == nil
+
+	if exists {
+		file.Status = StatusUpdate
+		file.Previous = current
+	}
+
+	if exists && bytes.Equal(current, src) {
+		if err := manifest.record(dstPath, inputs, src); err != nil {
+			return err
+		}
+
+		file.Status = StatusIdentical
+		manifest.add(dstPath, file)
+
+		return nil
+	}
+
+	if exists && manifest != nil && !manifest.force {
+		if _, e := manifest.entry(dstPath); e != nil && e.Output != hash(current) {
+			base, ok := manifest.generated(dstPath)
+			if !ok {
+				file.Status, file.Reason = StatusConflict, "edited since the last generation"
+				manifest.add(dstPath, file)
+
+				return nil
+			}
+
+			merged, conflicts := merge3(string(base), string(current), string(src))
+			if conflicts > 0 {
+				file.Status, file.Reason = StatusConflict, "the changes conflict with the edits since the last generation"
+				manifest.add(dstPath, file)
+
+				return nil
+			}
+
+			// the generation changes nothing in the file, e.g. when only the inputs of the other files are changed
+			if merged == string(current) {
+				if err := manifest.record(dstPath, inputs, src); err != nil {
+					return err
+				}
+
+				file.Status, file.Reason, file.Content = StatusKeep, "edited since the last generation", current
+				manifest.add(dstPath, file)
+
+				return nil
+			}
+
+			file.Status = StatusMerge
+			file.Content = []byte(merged)
+		}
+	}
+
+	manifest.add(dstPath, file)
+
+	if manifest != nil && manifest.dryRun {
+		return nil
+	}
+
+	if !util.FileExists(filepath.Dir(dstPath)) {
+		if err := util.Mkdir(filepath.Dir(dstPath)); err != nil {
+			return err
+		}
+	}
+
+	if err := ioutil.WriteFile(dstPath, file.Content, 0644); err != nil {
+		return err
+	}
+
+	return manifest.record(dstPath, inputs, src)
+}
+
+func hash(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// inputsHash returns the hash of everything the file is generated from, i.e. the template, the project settings
+// and the models including the ones reachable through their associations.
+func inputsHash(body []byte, detail *Detail, models []*Model) string {
+	h := sha256.New()
+
+	h.Write(body)
+	fmt.Fprintf(h, "\x00%s\x00%s", Version, assetsHash())
+	fmt.Fprintf(h, "\x00%s\x00%s\x00%s\x00%s\x00%s\x00%s\x00", detail.VCS, detail.User, detail.Project, detail.Namespace, detail.ImportDir, detail.Database)
+
+	// the functions written in Go are hashed by their names and the version given by the caller
+	names := make([]string, 0, len(detail.funcs))
+	for name := range detail.funcs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(h, "func %s %s\x00", name, detail.funcDefs[name])
+	}
+
+	fmt.Fprintf(h, "funcs %s\x00", detail.funcsVersion)
+
+	visited := make(map[*Model]bool)
+
+	for _, model := range models {
+		writeModel(h, model, visited)
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func writeModel(w io.Writer, model *Model, visited map[*Model]bool) {
+	if visited[model] {
+		fmt.Fprintf(w, "model %s\n", model.Name)
+		return
+	}
+
+	visited[model] = true
+
+	fmt.Fprintf(w, "model %s %s %v %v\n", model.Name, model.Path, model.Skip, model.ReadOnly)
+
+	if model.Package != nil {
+		fmt.Fprintf(w, "package %s %s %s\n", model.Package.Dir, model.Package.Name, model.Package.Alias)
+	}
+
+	for _, field := range model.Fields {
+		values := append([]string(nil), field.PolymorphicValues...)
+		sort.Strings(values)
+
+		fmt.Fprintf(w, "field %s %s %s %q %v %v %v %v %v %v %v\n", field.Name, field.JSONName, field.Type, field.Tag, values,
+			field.Hidden, field.ReadOnly, field.WriteOnly, field.Filterable, field.Sortable, field.Required)
+
+		// the underlying type tells JSON documents and arrays declared as named types
+		if field.GoType != nil {
+			fmt.Fprintf(w, "type %s\n", types.TypeString(field.GoType.Underlying(), nil))
+		}
+
+		if assoc := field.Association; assoc != nil {
+			fmt.Fprintf(w, "association %d %s %s %s %s\n", assoc.Type, assoc.ForeignKey, assoc.JoinTable, assoc.Polymorphic, assoc.PolymorphicValue)
+
+			if assoc.Model != nil {
+				writeModel(w, assoc.Model, visited)
+			}
+		}
+	}
+}
+
+// assetsHash returns the hash of all the built-in templates, as a template may be rendered by the others and
+// the changes of them by a new apig are missed by the hash of the template itself.
+func assetsHash() string {
+	assetsOnce.Do(func() {
+		names := AssetNames()
+		sort.Strings(names)
+
+		h := sha256.New()
+
+		for _, name := range names {
+			body, _ := Asset(name)
+
+			fmt.Fprintf(h, "%s\x00", name)
+			h.Write(body)
+			h.Write([]byte{0})
+		}
+
+		assetsSum = hex.EncodeToString(h.Sum(nil))
+	})
+
+	return assetsSum
+}
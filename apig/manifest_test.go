@@ -0,0 +1,292 @@
+package apig
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestManifest(t *testing.T) {
+	outDir, err := ioutil.TempDir("", "manifest")
+	if err != nil {
+		t.Fatal("Failed to create tempdir")
+	}
+	defer os.RemoveAll(outDir)
+
+	manifest, err := loadManifest(outDir)
+	if err != nil {
+		t.Fatalf("Error should not be raised: %s", err)
+	}
+
+	path := filepath.Join(outDir, "controllers", "user.go")
+
+	if err := writeFile(manifest, path, "v1", []byte("package controllers\n\nfunc A() {}\n\nfunc B() {}\n")); err != nil {
+		t.Fatalf("Error should not be raised: %s", err)
+	}
+
+	if err := manifest.save(); err != nil {
+		t.Fatalf("Error should not be raised: %s", err)
+	}
+
+	manifest, err = loadManifest(outDir)
+	if err != nil {
+		t.Fatalf("Error should not be raised: %s", err)
+	}
+
+	if !manifest.unchanged(path, "v1") {
+		t.Fatalf("File generated from the same inputs should be unchanged.")
+	}
+
+	if manifest.unchanged(path, "v2") {
+		t.Fatalf("File generated from the other inputs should not be unchanged.")
+	}
+
+	edited := "package controllers\n\nfunc A() {}\n\nfunc B() {}\n\n// edited by hand\n"
+
+	if err := ioutil.WriteFile(path, []byte(edited), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if !manifest.unchanged(path, "v1") {
+		t.Fatalf("File edited by hand should be kept when the inputs are not changed.")
+	}
+
+	if err := writeFile(manifest, path, "v2", []byte("package controllers\n\nfunc A() { return }\n\nfunc B() {}\n")); err != nil {
+		t.Fatalf("Error should not be raised: %s", err)
+	}
+
+	expected := "package controllers\n\nfunc A() { return }\n\nfunc B() {}\n\n// edited by hand\n"
+
+	if content, _ := ioutil.ReadFile(path); string(content) != expected {
+		t.Fatalf("Edits by hand should be merged. expected: %q, actual: %q", expected, content)
+	}
+
+	manifest.files = nil
+
+	if err := writeFile(manifest, path, "v2.1", []byte("package controllers\n\nfunc A() { return }\n\nfunc B() {}\n")); err != nil {
+		t.Fatalf("Error should not be raised: %s", err)
+	}
+
+	if changed := (&Result{Files: manifest.result()}).Changed(); len(changed) != 0 {
+		t.Fatalf("File should be kept when the generation changes nothing in it. actual: %v", changed)
+	}
+
+	if manifest.unchanged(path, "v2") || !manifest.unchanged(path, "v2.1") {
+		t.Fatalf("Inputs of the kept file should be recorded.")
+	}
+
+	if err := writeFile(manifest, path, "v3", []byte("package controllers\n\nfunc A() { return }\n\nfunc B() {}\n\nfunc C() {}\n")); err != nil {
+		t.Fatalf("Error should not be raised: %s", err)
+	}
+
+	if content, _ := ioutil.ReadFile(path); string(content) != expected {
+		t.Fatalf("File should not be overwritten when the edits conflict. actual: %q", content)
+	}
+
+	result := &Result{Files: manifest.result()}
+
+	if conflicts := result.Conflicts(); len(conflicts) != 1 || conflicts[0].Path != "controllers/user.go" {
+		t.Fatalf("Conflict should be reported. actual: %v", conflicts)
+	}
+
+	manifest.force = true
+
+	if err := writeFile(manifest, path, "v3", []byte("package controllers\n\nfunc B() {}\n")); err != nil {
+		t.Fatalf("Error should not be raised: %s", err)
+	}
+
+	if content, _ := ioutil.ReadFile(path); string(content) != "package controllers\n\nfunc B() {}\n" {
+		t.Fatalf("File edited by hand should be overwritten by force. actual: %q", content)
+	}
+}
+
+func TestManifest_DryRun(t *testing.T) {
+	outDir, err := ioutil.TempDir("", "manifest")
+	if err != nil {
+		t.Fatal("Failed to create tempdir")
+	}
+	defer os.RemoveAll(outDir)
+
+	manifest, err := loadManifest(outDir)
+	if err != nil {
+		t.Fatalf("Error should not be raised: %s", err)
+	}
+
+	manifest.dryRun = true
+
+	path := filepath.Join(outDir, "controllers", "user.go")
+
+	if err := writeFile(manifest, path, "v1", []byte("package controllers\n")); err != nil {
+		t.Fatalf("Error should not be raised: %s", err)
+	}
+
+	if err := manifest.save(); err != nil {
+		t.Fatalf("Error should not be raised: %s", err)
+	}
+
+	for _, p := range []string{path, filepath.Join(outDir, manifestFile), filepath.Join(outDir, generatedDir)} {
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			t.Fatalf("%s should not be written in the dry run.", p)
+		}
+	}
+}
+
+func TestManifest_Result(t *testing.T) {
+	outDir, err := ioutil.TempDir("", "manifest")
+	if err != nil {
+		t.Fatal("Failed to create tempdir")
+	}
+	defer os.RemoveAll(outDir)
+
+	manifest, err := loadManifest(outDir)
+	if err != nil {
+		t.Fatalf("Error should not be raised: %s", err)
+	}
+
+	userPath := filepath.Join(outDir, "controllers", "user.go")
+	routerPath := filepath.Join(outDir, "router", "router.go")
+
+	if err := writeFile(manifest, userPath, "v1", []byte("package controllers\n")); err != nil {
+		t.Fatalf("Error should not be raised: %s", err)
+	}
+
+	manifest, err = loadManifest(outDir)
+	if err != nil {
+		t.Fatalf("Error should not be raised: %s", err)
+	}
+
+	manifest.dryRun = true
+
+	if err := writeFile(manifest, userPath, "v2", []byte("package controllers\n\nfunc A() {}\n")); err != nil {
+		t.Fatalf("Error should not be raised: %s", err)
+	}
+
+	if err := writeFile(manifest, routerPath, "v1", []byte("package router\n")); err != nil {
+		t.Fatalf("Error should not be raised: %s", err)
+	}
+
+	files := manifest.result()
+
+	if len(files) != 2 {
+		t.Fatalf("Incorrect number of files. expected: 2, actual: %d", len(files))
+	}
+
+	if f := files[0]; f.Path != "controllers/user.go" || f.Status != StatusUpdate || string(f.Previous) != "package controllers\n" || string(f.Content) != "package controllers\n\nfunc A() {}\n" {
+		t.Fatalf("Incorrect file. actual: %#v", f)
+	}
+
+	if f := files[1]; f.Path != "router/router.go" || f.Status != StatusCreate || f.Previous != nil {
+		t.Fatalf("Incorrect file. actual: %#v", f)
+	}
+
+	if content, _ := ioutil.ReadFile(userPath); string(content) != "package controllers\n" {
+		t.Fatalf("File should not be written in the dry run. actual: %q", content)
+	}
+
+	if changed := (&Result{Files: files}).Changed(); len(changed) != 2 {
+		t.Fatalf("Files should be out of date. actual: %v", changed)
+	}
+}
+
+func TestInputsHash(t *testing.T) {
+	body := []byte("{{ .Model.Name }}")
+	h1 := inputsHash(body, detail, []*Model{userModel})
+
+	if h2 := inputsHash(body, detail, []*Model{userModel}); h1 != h2 {
+		t.Fatalf("Hash of the same inputs should be the same. expected: %s, actual: %s", h1, h2)
+	}
+
+	fields := append([]*Field{}, userModel.Fields...)
+	user := &Model{Name: "User", Fields: append(fields, &Field{Name: "Nickname", JSONName: "nickname", Type: "string"})}
+
+	if h2 := inputsHash(body, detail, []*Model{user}); h1 == h2 {
+		t.Fatalf("Hash should be changed when the model is changed.")
+	}
+
+	if h2 := inputsHash([]byte("{{ .Model.Name }}\n"), detail, []*Model{userModel}); h1 == h2 {
+		t.Fatalf("Hash should be changed when the template is changed.")
+	}
+
+	d := *detail
+	d.funcs = template.FuncMap{"shout": strings.ToUpper}
+
+	if h2 := inputsHash(body, &d, []*Model{userModel}); h1 == h2 {
+		t.Fatalf("Hash should be changed when the function is added.")
+	}
+
+	h3 := inputsHash(body, &d, []*Model{userModel})
+	d.funcsVersion = "2"
+
+	if h2 := inputsHash(body, &d, []*Model{userModel}); h2 == h3 {
+		t.Fatalf("Hash should be changed when the version of the functions is changed.")
+	}
+
+	defer func(v string) { Version = v }(Version)
+	Version = "9.9.9"
+
+	if h2 := inputsHash(body, detail, []*Model{userModel}); h1 == h2 {
+		t.Fatalf("Hash should be changed when apig is upgraded.")
+	}
+}
+
+func TestGenerate_Failure(t *testing.T) {
+	outDir, err := ioutil.TempDir("", "failure")
+	if err != nil {
+		t.Fatal("Failed to create tempdir")
+	}
+	defer os.RemoveAll(outDir)
+
+	if err := copyDir(filepath.Join("testdata", "legacy"), outDir); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Generate(&Options{OutDir: outDir}); err != nil {
+		t.Fatalf("Error should not be raised: %s", err)
+	}
+
+	templates := filepath.Join(outDir, "templates")
+
+	if err := os.MkdirAll(templates, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	body, err := readTemplate("", "controller.go.tmpl")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(templates, "controller.go.tmpl"), append([]byte("// custom\n"), body...), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// the router is generated after the controllers
+	if err := ioutil.WriteFile(filepath.Join(templates, "router.go.tmpl"), []byte("{{ .Unknown }}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Generate(&Options{OutDir: outDir, Templates: templates}); err == nil {
+		t.Fatalf("Error should be raised when the template fails.")
+	}
+
+	if body, err := ioutil.ReadFile(filepath.Join(outDir, "controllers", "user.go")); err != nil || !strings.HasPrefix(string(body), "// custom") {
+		t.Fatalf("Controller should be written before the failure. error: %v", err)
+	}
+
+	result, err := Generate(&Options{OutDir: outDir})
+	if err != nil {
+		t.Fatalf("Error should not be raised: %s", err)
+	}
+
+	for _, f := range result.Files {
+		if f.Status == StatusKeep || f.Status == StatusConflict {
+			t.Fatalf("Files written before the failure should not be regarded as edited by hand. actual: %s %s", f.Status, f.Path)
+		}
+	}
+
+	if body, _ := ioutil.ReadFile(filepath.Join(outDir, "controllers", "user.go")); strings.HasPrefix(string(body), "// custom") {
+		t.Fatalf("Controller should be generated again by the embedded template.")
+	}
+}
@@ -0,0 +1,186 @@
+package apig
+
+import (
+	"strings"
+)
+
+// splitLines splits the content into lines keeping their line breaks, so that joining them restores the content.
+func splitLines(s string) []string {
+	if s == "" {
+		return []string{}
+	}
+
+	lines := strings.SplitAfter(s, "\n")
+
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// matchLines returns the pairs of the indexes of the lines common to a and b in ascending order,
+// i.e. their longest common subsequence found by Myers' algorithm.
+func matchLines(a, b []string) [][2]int {
+	matches := [][2]int{}
+
+	// the common prefix and suffix are matched without the search, which is often the most of generated files
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		matches = append(matches, [2]int{prefix, prefix})
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	for _, m := range shortestEdit(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]) {
+		matches = append(matches, [2]int{prefix + m[0], prefix + m[1]})
+	}
+
+	for i := suffix; i > 0; i-- {
+		matches = append(matches, [2]int{len(a) - i, len(b) - i})
+	}
+
+	return matches
+}
+
+func shortestEdit(a, b []string) [][2]int {
+	n, m := len(a), len(b)
+	max := n + m
+	v := make([]int, 2*max+3)
+	offset := max + 1
+
+	// trace[d] holds v[offset-d-1:offset+d+2] before the d-th step, by which the path is backtracked
+	trace := [][]int{}
+
+	for d := 0; d <= max; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d-1:offset+d+2]...))
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+
+			y := x - k
+
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				return backtrack(trace, n, m, d)
+			}
+		}
+	}
+
+	return nil
+}
+
+func backtrack(trace [][]int, x, y, d int) [][2]int {
+	matches := [][2]int{}
+
+	for ; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+
+		var prevK int
+
+		if k == -d || (k != d && v[k-1+d+1] < v[k+1+d+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+
+		prevX := v[prevK+d+1]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			matches = append(matches, [2]int{x, y})
+		}
+
+		if d > 0 {
+			x, y = prevX, prevY
+		}
+	}
+
+	for i, j := 0, len(matches)-1; i < j; i, j = i+1, j-1 {
+		matches[i], matches[j] = matches[j], matches[i]
+	}
+
+	return matches
+}
+
+// merge3 applies both the changes from base to ours and the ones from base to theirs, e.g. the edits by hand
+// and the ones by the new generation. It returns the number of the regions changed differently in both.
+func merge3(base, ours, theirs string) (string, int) {
+	b, o, t := splitLines(base), splitLines(ours), splitLines(theirs)
+
+	oursMatch := make(map[int]int)
+	for _, m := range matchLines(b, o) {
+		oursMatch[m[0]] = m[1]
+	}
+
+	theirsMatch := make(map[int]int)
+	for _, m := range matchLines(b, t) {
+		theirsMatch[m[0]] = m[1]
+	}
+
+	var merged []string
+	conflicts := 0
+	i, j, k := 0, 0, 0
+
+	resolve := func(bs, os, ts []string) {
+		switch {
+		case equalLines(os, bs):
+			merged = append(merged, ts...)
+		case equalLines(ts, bs), equalLines(os, ts):
+			merged = append(merged, os...)
+		default:
+			conflicts++
+		}
+	}
+
+	for x := range b {
+		y, ok1 := oursMatch[x]
+		z, ok2 := theirsMatch[x]
+
+		// lines unchanged in both sides split the content into the regions merged independently
+		if !ok1 || !ok2 || y < j || z < k {
+			continue
+		}
+
+		resolve(b[i:x], o[j:y], t[k:z])
+		merged = append(merged, b[x])
+		i, j, k = x+1, y+1, z+1
+	}
+
+	resolve(b[i:], o[j:], t[k:])
+
+	return strings.Join(merged, ""), conflicts
+}
+
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,65 @@
+package apig
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMatchLines(t *testing.T) {
+	a := splitLines("a\nb\nc\nd\ne\n")
+	b := splitLines("a\nc\nx\nd\ne\ny\n")
+
+	matches := matchLines(a, b)
+	expected := [][2]int{{0, 0}, {2, 1}, {3, 3}, {4, 4}}
+
+	if len(matches) != len(expected) {
+		t.Fatalf("Incorrect matches. expected: %v, actual: %v", expected, matches)
+	}
+
+	for i := range expected {
+		if matches[i] != expected[i] {
+			t.Fatalf("Incorrect matches. expected: %v, actual: %v", expected, matches)
+		}
+	}
+}
+
+func TestMerge3(t *testing.T) {
+	base := strings.Join([]string{"package controllers", "", "func A() {", "}", "", "func B() {", "}", ""}, "\n")
+
+	cases := []struct {
+		ours      string
+		theirs    string
+		expected  string
+		conflicts int
+	}{
+		{
+			ours:      strings.Replace(base, "func A() {\n", "func A() {\n\tvalidate()\n", 1),
+			theirs:    strings.Replace(base, "func B() {\n", "func B() {\n\tlog()\n", 1),
+			expected:  strings.Replace(strings.Replace(base, "func A() {\n", "func A() {\n\tvalidate()\n", 1), "func B() {\n", "func B() {\n\tlog()\n", 1),
+			conflicts: 0,
+		},
+		{
+			ours:      strings.Replace(base, "func A() {\n", "func A() {\n\tvalidate()\n", 1),
+			theirs:    base,
+			expected:  strings.Replace(base, "func A() {\n", "func A() {\n\tvalidate()\n", 1),
+			conflicts: 0,
+		},
+		{
+			ours:      strings.Replace(base, "func A() {\n", "func A() {\n\tvalidate()\n", 1),
+			theirs:    strings.Replace(base, "func A() {\n", "func A() {\n\tlog()\n", 1),
+			conflicts: 1,
+		},
+	}
+
+	for i, c := range cases {
+		merged, conflicts := merge3(base, c.ours, c.theirs)
+
+		if conflicts != c.conflicts {
+			t.Fatalf("Incorrect number of conflicts in case %d. expected: %d, actual: %d", i, c.conflicts, conflicts)
+		}
+
+		if conflicts == 0 && merged != c.expected {
+			t.Fatalf("Incorrect merge in case %d. expected: %q, actual: %q", i, c.expected, merged)
+		}
+	}
+}
@@ -1,15 +1,95 @@
 package apig
 
+import (
+	"go/types"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/gedex/inflector"
+	"github.com/serenize/snaker"
+)
+
 const (
-	AssociationNone      = 0
-	AssociationBelongsTo = 1
-	AssociationHasMany   = 2
-	AssociationHasOne    = 3
+	AssociationNone       = 0
+	AssociationBelongsTo  = 1
+	AssociationHasMany    = 2
+	AssociationHasOne     = 3
+	AssociationManyToMany = 4
 )
 
+// Package is a package of models, e.g. models or models/billing.
+type Package struct {
+	// Dir is the directory of the package relative to the models directory, which is empty for models itself.
+	Dir  string
+	Name string
+
+	// Alias is the name by which the generated code refers to the package.
+	Alias string
+}
+
+// ImportPath returns the import path relative to the project, e.g. models/billing
+func (p *Package) ImportPath() string {
+	return path.Join("models", p.Dir)
+}
+
+func (p *Package) IsAliased() bool {
+	return p.Alias != p.Name
+}
+
 type Model struct {
-	Name   string
-	Fields []*Field
+	Name    string
+	Fields  []*Field
+	Type    types.Type
+	Package *Package
+
+	// Directives given in the doc comment of the model, e.g. `// apig:readonly`
+	Skip     bool
+	ReadOnly bool
+	Path     string
+}
+
+// Resource returns the path of the model's routes, e.g. users or billing/invoices for the models in subpackages.
+func (m *Model) Resource() string {
+	return path.Join(m.Group(), m.Route())
+}
+
+// Route returns the path of the model's routes in its group, e.g. invoices, or the one given by `// apig:path=`.
+func (m *Model) Route() string {
+	if m.Path != "" {
+		return m.Path
+	}
+
+	return inflector.Pluralize(snaker.CamelToSnake(m.Name))
+}
+
+// Group returns the path of the route group which follows the package layout, e.g. billing for models/billing.
+func (m *Model) Group() string {
+	if m.Package == nil {
+		return ""
+	}
+
+	return m.Package.Dir
+}
+
+// QualifiedName returns the name referring to the model in the generated code, e.g. models.User or billing.Invoice
+func (m *Model) QualifiedName() string {
+	if m.Package == nil {
+		return "models." + m.Name
+	}
+
+	return m.Package.Alias + "." + m.Name
+}
+
+// Imports returns the packages which the controller of the model refers to. The linked models are referred to only
+// by the handlers writing associations, which readonly models do not have.
+func (m *Model) Imports() []*Package {
+	if m.ReadOnly {
+		return modelPackages([]*Model{m})
+	}
+
+	return modelPackages(append([]*Model{m}, linkedModels(m)...))
 }
 
 func (m *Model) AllPreloadAssocs() []string {
@@ -22,6 +102,159 @@ func (m *Model) AllPreloadAssocs() []string {
 	return result
 }
 
+// PrimaryKey returns the field tagged with `gorm:"primary_key"`, or the field named ID as gorm does.
+// The default numeric ID is returned when the model has neither of them.
+func (m *Model) PrimaryKey() *Field {
+	for _, field := range m.Fields {
+		if _, ok := field.GormSetting("primary_key"); ok {
+			return field
+		}
+	}
+
+	for _, field := range m.Fields {
+		if field.Name == "ID" {
+			return field
+		}
+	}
+
+	return &Field{Name: "ID", JSONName: "id", Type: "uint"}
+}
+
+// PrimaryKeys returns all fields tagged with `gorm:"primary_key"` for composite primary keys,
+// otherwise returns the field given by PrimaryKey.
+func (m *Model) PrimaryKeys() []*Field {
+	fields := []*Field{}
+
+	for _, field := range m.Fields {
+		if _, ok := field.GormSetting("primary_key"); ok {
+			fields = append(fields, field)
+		}
+	}
+
+	if len(fields) == 0 {
+		fields = append(fields, m.PrimaryKey())
+	}
+
+	return fields
+}
+
+func (m *Model) IsCompositeKey() bool {
+	return len(m.PrimaryKeys()) > 1
+}
+
+// IDParams returns the names of the path parameters which identify a record, e.g. id or user_id, tag_id
+func (m *Model) IDParams() []string {
+	if !m.IsCompositeKey() {
+		return []string{"id"}
+	}
+
+	params := []string{}
+
+	for _, field := range m.PrimaryKeys() {
+		params = append(params, field.ParamName())
+	}
+
+	return params
+}
+
+// KeyCondition returns the SQL condition to look up a record by the primary keys, e.g. user_id = ? AND tag_id = ?
+func (m *Model) KeyCondition() string {
+	conditions := []string{}
+
+	for _, field := range m.PrimaryKeys() {
+		conditions = append(conditions, field.Column()+" = ?")
+	}
+
+	return strings.Join(conditions, " AND ")
+}
+
+// LinkFields returns many-to-many associations which can be linked through endpoints.
+// Models with composite primary keys are not supported in either side.
+func (m *Model) LinkFields() []*Field {
+	fields := []*Field{}
+
+	if m.IsCompositeKey() {
+		return fields
+	}
+
+	for _, field := range m.Fields {
+		if field.IsManyToMany() && !field.Association.Model.IsCompositeKey() {
+			fields = append(fields, field)
+		}
+	}
+
+	return fields
+}
+
+// HasHiddenFields reports whether the model has fields which must not be exposed in responses.
+func (m *Model) HasHiddenFields() bool {
+	for _, field := range m.Fields {
+		if !field.IsReadable() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HasReadOnlyFields reports whether the model has fields which cannot be written through requests.
+func (m *Model) HasReadOnlyFields() bool {
+	for _, field := range m.Fields {
+		if !field.IsWritable() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HasRequiredFields reports whether the model has fields which must be given in requests.
+func (m *Model) HasRequiredFields() bool {
+	for _, field := range m.Fields {
+		if field.Required {
+			return true
+		}
+	}
+
+	return false
+}
+
+func linkedModels(m *Model) []*Model {
+	models := []*Model{}
+
+	for _, field := range m.LinkFields() {
+		models = append(models, field.Association.Model)
+	}
+
+	return models
+}
+
+// modelPackages returns the packages of the models sorted by their directories.
+func modelPackages(models []*Model) []*Package {
+	packages := []*Package{}
+	seen := make(map[string]bool)
+
+	for _, m := range models {
+		p := m.Package
+		if p == nil {
+			p = &Package{Name: "models", Alias: "models"}
+		}
+
+		if seen[p.Dir] {
+			continue
+		}
+
+		seen[p.Dir] = true
+		packages = append(packages, p)
+	}
+
+	sort.Slice(packages, func(i, j int) bool {
+		return packages[i].Dir < packages[j].Dir
+	})
+
+	return packages
+}
+
 type Models []*Model // implements Sort interface
 
 func (m Models) Len() int {
@@ -41,7 +274,19 @@ type Field struct {
 	JSONName    string
 	Type        string
 	Tag         string
+	GoType      types.Type
 	Association *Association
+
+	// PolymorphicValues holds the values allowed in the type column of polymorphic associations, e.g. OwnerType
+	PolymorphicValues []string
+
+	// API behavior given by apig tag, e.g. `apig:"readonly,sortable"`
+	Hidden     bool
+	ReadOnly   bool
+	WriteOnly  bool
+	Filterable bool
+	Sortable   bool
+	Required   bool
 }
 
 func (f *Field) PreloadAssocs() []string {
@@ -64,6 +309,126 @@ func (f *Field) PreloadAssocs() []string {
 	return result
 }
 
+// BasicType returns the name of the underlying basic type when the field is declared with
+// a named type such as `type Status string`, otherwise returns Type as it is.
+func (f *Field) BasicType() string {
+	if f.GoType == nil {
+		return f.Type
+	}
+
+	named, ok := f.GoType.(*types.Named)
+	if !ok {
+		return f.Type
+	}
+
+	if basic, ok := named.Underlying().(*types.Basic); ok && basic.Kind() != types.Invalid {
+		return basic.Name()
+	}
+
+	return f.Type
+}
+
+// GormSetting returns the value of the key in gorm struct tag, e.g. `gorm:"many2many:user_tags"`.
+// Keys are case insensitive as gorm does.
+func (f *Field) GormSetting(key string) (string, bool) {
+	for _, s := range strings.Split(reflect.StructTag(f.Tag).Get("gorm"), ";") {
+		kv := strings.SplitN(s, ":", 2)
+
+		if !strings.EqualFold(strings.TrimSpace(kv[0]), key) {
+			continue
+		}
+
+		if len(kv) == 2 {
+			return strings.TrimSpace(kv[1]), true
+		}
+
+		return "", true
+	}
+
+	return "", false
+}
+
+// Column returns the column name of the field in the table, e.g. `gorm:"column:user_name"`.
+func (f *Field) Column() string {
+	if column, ok := f.GormSetting("column"); ok && column != "" {
+		return column
+	}
+
+	return snaker.CamelToSnake(f.Name)
+}
+
+// ParamName returns the name of the path parameter of the field, e.g. UserID -> user_id
+func (f *Field) ParamName() string {
+	return snaker.CamelToSnake(f.Name)
+}
+
+// VarName returns the name of the local variable of the field in generated code, e.g. UserID -> userID
+func (f *Field) VarName() string {
+	words := strings.SplitN(f.ParamName(), "_", 2)
+
+	if len(words) == 1 {
+		return words[0]
+	}
+
+	return words[0] + snaker.SnakeToCamel(words[1])
+}
+
+// IsJSON reports whether the field holds a JSON document, e.g. map[string]interface{}, json.RawMessage or postgres.Jsonb.
+func (f *Field) IsJSON() bool {
+	switch strings.TrimPrefix(f.Type, "*") {
+	case "json.RawMessage", "postgres.Jsonb":
+		return true
+	}
+
+	if f.GoType == nil {
+		return strings.HasPrefix(f.Type, "map[")
+	}
+
+	_, ok := f.GoType.Underlying().(*types.Map)
+	return ok
+}
+
+// ElemType returns the element type of slices and arrays which are not associations, e.g. []string -> string
+func (f *Field) ElemType() (*Field, bool) {
+	if f.IsAssociation() || !strings.HasPrefix(f.Type, "[") {
+		return nil, false
+	}
+
+	elem := &Field{
+		Name: f.Name,
+		Type: f.Type[strings.Index(f.Type, "]")+1:],
+	}
+
+	if f.GoType != nil {
+		switch x := f.GoType.Underlying().(type) {
+		case *types.Slice:
+			elem.GoType = x.Elem()
+		case *types.Array:
+			elem.GoType = x.Elem()
+		}
+	}
+
+	return elem, true
+}
+
+// IsNumeric reports whether the field holds integers, e.g. auto-incremented IDs.
+func (f *Field) IsNumeric() bool {
+	switch f.BasicType() {
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+		return true
+	}
+
+	return false
+}
+
+func (f *Field) IsReadable() bool {
+	return !f.Hidden && !f.WriteOnly
+}
+
+func (f *Field) IsWritable() bool {
+	return !f.Hidden && !f.ReadOnly
+}
+
 func (f *Field) IsAssociation() bool {
 	return f.Association != nil && f.Association.Type != AssociationNone
 }
@@ -72,7 +437,19 @@ func (f *Field) IsBelongsTo() bool {
 	return f.Association != nil && f.Association.Type == AssociationBelongsTo
 }
 
+func (f *Field) IsPolymorphic() bool {
+	return f.Association != nil && f.Association.Polymorphic != ""
+}
+
+func (f *Field) IsManyToMany() bool {
+	return f.Association != nil && f.Association.Type == AssociationManyToMany
+}
+
 type Association struct {
-	Type  int
-	Model *Model
+	Type             int
+	Model            *Model
+	ForeignKey       string
+	JoinTable        string
+	Polymorphic      string
+	PolymorphicValue string
 }
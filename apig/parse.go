@@ -1,137 +1,672 @@
 package apig
 
 import (
-	"errors"
+	"fmt"
 	"go/ast"
+	"go/importer"
 	"go/parser"
+	"go/scanner"
 	"go/token"
+	"go/types"
+	"path"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 )
 
-func parseField(field *ast.Field) (*Field, error) {
-	if len(field.Names) != 1 {
-		return nil, errors.New("Failed to read model files. Please fix struct.")
+// knownEmbeddedFields returns the fields of well-known structs which are often embedded in models.
+// They are used when the package of the embedded struct cannot be loaded.
+func knownEmbeddedFields(typeName string) ([]*Field, bool) {
+	switch typeName {
+	case "gorm.Model":
+		return []*Field{
+			&Field{Name: "ID", JSONName: "ID", Type: "uint", Tag: `gorm:"primary_key"`},
+			&Field{Name: "CreatedAt", JSONName: "CreatedAt", Type: "time.Time"},
+			&Field{Name: "UpdatedAt", JSONName: "UpdatedAt", Type: "time.Time"},
+			&Field{Name: "DeletedAt", JSONName: "DeletedAt", Type: "*time.Time", Tag: `sql:"index"`},
+		}, true
 	}
 
-	fieldName := field.Names[0].Name
+	return nil, false
+}
+
+func parseField(v *types.Var, tag string, qf types.Qualifier) (*Field, error) {
+	if err := validateTag(tag); err != nil {
+		return nil, fmt.Errorf("malformed tag of field %s: %s", v.Name(), err)
+	}
+
+	if !supportedType(v.Type()) {
+		return nil, fmt.Errorf("unsupported type %s of field %s", types.TypeString(v.Type(), qf), v.Name())
+	}
+
+	fieldName := v.Name()
+	fieldType := types.TypeString(v.Type(), qf)
+
+	jsonName := strings.Split(reflect.StructTag(tag).Get("json"), ",")[0]
+
+	if jsonName == "" {
+		jsonName = fieldName
+	}
+
+	fs := Field{
+		Name:     fieldName,
+		JSONName: jsonName,
+		Type:     fieldType,
+		Tag:      tag,
+		GoType:   v.Type(),
+	}
 
-	var fieldType string
+	if err := parseOptions(&fs, reflect.StructTag(tag).Get("apig")); err != nil {
+		return nil, err
+	}
+
+	return &fs, nil
+}
 
-	switch x := field.Type.(type) {
-	case *ast.Ident: // e.g. string
-		fieldType = x.Name
+// parseOptions sets the API behavior given by apig tag, e.g. `apig:"readonly,sortable"`.
+func parseOptions(field *Field, options string) error {
+	if options == "" {
+		return nil
+	}
 
-	case *ast.SelectorExpr: // e.g. time.Time, sql.NullString
-		switch x2 := x.X.(type) {
-		case *ast.Ident:
-			fieldType = x2.Name + "." + x.Sel.Name
+	for _, option := range strings.Split(options, ",") {
+		switch strings.TrimSpace(option) {
+		case "hidden":
+			field.Hidden = true
+		case "readonly":
+			field.ReadOnly = true
+		case "writeonly":
+			field.WriteOnly = true
+		case "filterable":
+			field.Filterable = true
+		case "sortable":
+			field.Sortable = true
+		case "required":
+			field.Required = true
+		default:
+			return fmt.Errorf("unknown apig option %q of field %s", option, field.Name)
 		}
+	}
+
+	if field.ReadOnly && field.WriteOnly {
+		return fmt.Errorf("field %s cannot be both readonly and writeonly", field.Name)
+	}
+
+	return nil
+}
+
+// parseModel loads models from the given files of one package.
+// All problems found in the files are returned as scanner.ErrorList with their positions.
+func parseModel(paths []string) ([]*Model, error) {
+	models, _, err := parsePackages(map[string][]string{"": paths}, "")
+	return models, err
+}
+
+// parsePackages loads models from the files of the packages keyed by their directories relative to the models
+// directory. importPath is the import path of the models directory, by which the packages import each other.
+// The packages are type-checked in dependency order so that associations across them are resolved.
+// The helper structs skipped because of their problems are reported as warnings.
+func parsePackages(dirs map[string][]string, importPath string) ([]*Model, []string, error) {
+	var errs scanner.ErrorList
+
+	fset := token.NewFileSet()
+	pkgFiles := make(map[string][]*ast.File)
+	pkgDirs := make(map[string]string)
+	dirNames := make([]string, 0, len(dirs))
 
-	case *ast.ArrayType: // e.g. []Email
-		switch x2 := x.Elt.(type) {
-		case *ast.Ident:
-			fieldType = "[]" + x2.Name
+	for dir, paths := range dirs {
+		for _, p := range paths {
+			f, err := parser.ParseFile(fset, p, nil, parser.ParseComments|parser.AllErrors)
 
-		case *ast.StarExpr: // e.g. []*Email
-			switch x3 := x2.X.(type) {
-			case *ast.Ident:
-				fieldType = "[]*" + x3.Name
+			if err != nil {
+				if list, ok := err.(scanner.ErrorList); ok {
+					errs = append(errs, list...)
+					continue
+				}
+
+				return nil, nil, err
 			}
+
+			pkgFiles[dir] = append(pkgFiles[dir], f)
 		}
 
-	case *ast.StarExpr:
-		switch x2 := x.X.(type) {
-		case *ast.Ident: // e.g. *Profile
-			fieldType = "*" + x2.Name
+		pkgDirs[path.Join(importPath, dir)] = dir
+		dirNames = append(dirNames, dir)
+	}
+
+	if len(errs) > 0 {
+		errs.Sort()
+		return nil, nil, errs
+	}
 
-		case *ast.SelectorExpr: // e.g. *time.Time
-			switch x3 := x2.X.(type) {
-			case *ast.Ident:
-				fieldType = "*" + x3.Name + "." + x2.Sel.Name
+	sort.Strings(dirNames)
+
+	// Imports which cannot be resolved (e.g. dependencies not installed yet) must not abort loading,
+	// so those errors are ignored and unresolved field types fall back to their source expression.
+	imp := &modelImporter{
+		packages: make(map[string]*types.Package),
+		importer: importer.ForCompiler(fset, "source", nil),
+	}
+	conf := types.Config{
+		Importer:         imp,
+		IgnoreFuncBodies: true,
+		Error: func(err error) {
+			if e, ok := err.(types.Error); ok && !strings.HasPrefix(e.Msg, "could not import") {
+				errs.Add(fset.Position(e.Pos), e.Msg)
 			}
+		},
+	}
+
+	models := []*Model{}
+	warnings := []string{}
+	modelMap := make(map[string]*Model)
+	loaded := []*Model{}
+	embedded := make(map[types.Type]bool)
+	referenced := make(map[types.Type]bool)
+
+	for _, dir := range sortPackages(fset, dirNames, pkgFiles, pkgDirs, &errs) {
+		files := pkgFiles[dir]
+
+		if len(files) == 0 {
+			continue
+		}
+
+		pkgPath := path.Join(importPath, dir)
+		if pkgPath == "" {
+			pkgPath = files[0].Name.Name
+		}
+
+		info := &types.Info{
+			Defs: make(map[*ast.Ident]types.Object),
+		}
+
+		pkg, _ := conf.Check(pkgPath, fset, files, info)
+		imp.packages[pkgPath] = pkg
+
+		p := &Package{Dir: dir, Name: files[0].Name.Name, Alias: files[0].Name.Name}
+
+		for _, model := range loadModels(fset, files, pkg, info, embedded, referenced, &errs, &warnings) {
+			model.Package = p
+			loaded = append(loaded, model)
 		}
 	}
 
-	var jsonName string
-	var fieldTag string
+	for _, model := range loaded {
+		// base structs such as Timestamps are flattened into the models embedding them, so they are not models
+		if embedded[model.Type] && !referenced[model.Type] {
+			continue
+		}
+
+		// gorm names tables after models, so models in different packages must not share their names.
+		if other, ok := modelMap[model.Name]; ok {
+			errs.Add(fset.Position(model.Type.(*types.Named).Obj().Pos()), fmt.Sprintf("model %s is already declared in %s", model.Name, other.Package.ImportPath()))
+			continue
+		}
 
-	if field.Tag == nil {
-		jsonName = fieldName
-		fieldTag = ""
-	} else {
-		s, err := strconv.Unquote(field.Tag.Value)
+		modelMap[model.Name] = model
+		models = append(models, model)
+	}
 
-		if err != nil {
-			s = field.Tag.Value
+	if len(errs) > 0 {
+		errs.Sort()
+		errs.RemoveMultiples()
+		return nil, nil, errs
+	}
+
+	return models, warnings, nil
+}
+
+// modelImporter imports the model packages already type-checked, and the others from their source.
+type modelImporter struct {
+	packages map[string]*types.Package
+	importer types.Importer
+}
+
+func (i *modelImporter) Import(path string) (*types.Package, error) {
+	if pkg, ok := i.packages[path]; ok {
+		return pkg, nil
+	}
+
+	return i.importer.Import(path)
+}
+
+// sortPackages returns the directories of the packages in dependency order. Import cycles are reported as errors.
+func sortPackages(fset *token.FileSet, dirNames []string, pkgFiles map[string][]*ast.File, pkgDirs map[string]string, errs *scanner.ErrorList) []string {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+
+	order := []string{}
+	state := make(map[string]int)
+
+	var visit func(dir string)
+	visit = func(dir string) {
+		state[dir] = visiting
+
+		for _, f := range pkgFiles[dir] {
+			for _, spec := range f.Imports {
+				p, err := strconv.Unquote(spec.Path.Value)
+				if err != nil {
+					continue
+				}
+
+				dep, ok := pkgDirs[p]
+				if !ok || dep == dir {
+					continue
+				}
+
+				switch state[dep] {
+				case visiting:
+					errs.Add(fset.Position(spec.Pos()), fmt.Sprintf("import cycle not allowed: %s", p))
+				case 0:
+					visit(dep)
+				}
+			}
 		}
 
-		jsonName = strings.Split((reflect.StructTag)(s).Get("json"), ",")[0]
-		fieldTag = field.Tag.Value
+		state[dir] = visited
+		order = append(order, dir)
 	}
 
-	fs := Field{
-		Name:     fieldName,
-		JSONName: jsonName,
-		Type:     fieldType,
-		Tag:      fieldTag,
+	for _, dir := range dirNames {
+		if state[dir] == 0 {
+			visit(dir)
+		}
 	}
 
-	return &fs, nil
+	return order
 }
 
-func parseModel(path string) ([]*Model, error) {
-	fset := token.NewFileSet()
-	f, err := parser.ParseFile(fset, path, nil, 0)
+// loadModels returns the models declared as structs in the files of the type-checked package.
+// The types embedded in the structs and the ones referred to by their fields are recorded in embedded and referenced.
+// Structs without primary key are helpers rather than models, so their problems are reported as warnings.
+func loadModels(fset *token.FileSet, files []*ast.File, pkg *types.Package, info *types.Info, embedded, referenced map[types.Type]bool, errs *scanner.ErrorList, warnings *[]string) []*Model {
+	qf := func(p *types.Package) string {
+		if p == pkg {
+			return ""
+		}
 
-	if err != nil {
-		return nil, err
+		return p.Name()
 	}
 
 	models := []*Model{}
 
-	ast.Inspect(f, func(node ast.Node) bool {
-		switch x := node.(type) {
-		case *ast.GenDecl:
-			if x.Tok != token.TYPE {
-				break
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			x, ok := decl.(*ast.GenDecl)
+			if !ok || x.Tok != token.TYPE {
+				continue
 			}
 
 			for _, spec := range x.Specs {
-				var fields []*Field
+				x2, ok := spec.(*ast.TypeSpec)
+				if !ok {
+					continue
+				}
 
-				var modelName string
+				obj, ok := info.Defs[x2.Name].(*types.TypeName)
+				if !ok || obj.IsAlias() {
+					continue
+				}
 
-				switch x2 := spec.(type) {
-				case *ast.TypeSpec:
-					modelName = x2.Name.Name
+				st, ok := obj.Type().Underlying().(*types.Struct)
+				if !ok {
+					continue
+				}
 
-					switch x3 := x2.Type.(type) {
-					case *ast.StructType:
-						for _, field := range x3.Fields.List {
-							fs, err := parseField(field)
+				for i := 0; i < st.NumFields(); i++ {
+					if isEmbeddedStruct(st.Field(i), st.Tag(i)) {
+						embedded[elemType(st.Field(i).Type())] = true
+					} else {
+						referenced[elemType(st.Field(i).Type())] = true
+					}
+				}
 
-							if err != nil {
-								return false
-							}
+				fields, err := parseStruct(fset, st, x2.Type, qf, map[types.Type]bool{obj.Type(): true})
+				if err != nil {
+					if hasPrimaryKey(st, map[types.Type]bool{obj.Type(): true}) {
+						*errs = append(*errs, err.(scanner.ErrorList)...)
+						continue
+					}
 
-							fields = append(fields, fs)
-						}
+					for _, e := range err.(scanner.ErrorList) {
+						*warnings = append(*warnings, fmt.Sprintf("%s: %s, struct %s is skipped", e.Pos, e.Msg, obj.Name()))
 					}
 
-					models = append(models, &Model{
-						Name:   modelName,
-						Fields: fields,
-					})
+					continue
+				}
+
+				model := &Model{
+					Name:   obj.Name(),
+					Fields: fields,
+					Type:   obj.Type(),
+				}
+
+				doc := x2.Doc
+				if doc == nil && len(x.Specs) == 1 {
+					doc = x.Doc
+				}
+
+				if err := parseDirectives(fset, doc, model); err != nil {
+					*errs = append(*errs, err.(scanner.ErrorList)...)
+					continue
+				}
+
+				models = append(models, model)
+			}
+		}
+	}
+
+	return models
+}
+
+// parseDirectives applies the generation directives written in the doc comment of the model,
+// e.g. `// apig:skip`, `// apig:readonly` and `// apig:path=/people`.
+func parseDirectives(fset *token.FileSet, doc *ast.CommentGroup, model *Model) error {
+	var errs scanner.ErrorList
+
+	if doc == nil {
+		return nil
+	}
+
+	for _, c := range doc.List {
+		text := strings.TrimSpace(strings.TrimPrefix(c.Text, "//"))
+
+		if !strings.HasPrefix(text, "apig:") {
+			continue
+		}
+
+		kv := strings.SplitN(strings.TrimPrefix(text, "apig:"), "=", 2)
+
+		switch kv[0] {
+		case "skip":
+			model.Skip = true
+		case "readonly":
+			model.ReadOnly = true
+		case "path":
+			if len(kv) != 2 || strings.Trim(kv[1], "/ ") == "" {
+				errs.Add(fset.Position(c.Pos()), "apig:path requires a path, e.g. apig:path=/people")
+				continue
+			}
+
+			model.Path = strings.Trim(kv[1], "/ ")
+		default:
+			errs.Add(fset.Position(c.Pos()), fmt.Sprintf("unknown directive %s of model %s", text, model.Name))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+func parseStruct(fset *token.FileSet, st *types.Struct, expr ast.Expr, qf types.Qualifier, visited map[types.Type]bool) ([]*Field, error) {
+	var errs scanner.ErrorList
+	var fields []*Field
+
+	if x, ok := expr.(*ast.StructType); ok {
+		for _, field := range x.Fields.List {
+			if len(field.Names) > 1 {
+				errs.Add(fset.Position(field.Pos()), fmt.Sprintf("multiple field names %s in one declaration are not supported, please declare them separately", identNames(field.Names)))
+			}
+		}
+	}
+
+	// Fields declared directly take precedence over promoted fields with the same name.
+	names := map[string]bool{}
+
+	for i := 0; i < st.NumFields(); i++ {
+		if !isEmbeddedStruct(st.Field(i), st.Tag(i)) {
+			names[st.Field(i).Name()] = true
+		}
+	}
+
+	for i := 0; i < st.NumFields(); i++ {
+		v, tag := st.Field(i), st.Tag(i)
+
+		if isEmbeddedStruct(v, tag) {
+			embedded, err := parseEmbedded(fset, v, expr, qf, visited)
+
+			if err != nil {
+				if list, ok := err.(scanner.ErrorList); ok {
+					errs = append(errs, list...)
+				} else {
+					errs.Add(fset.Position(v.Pos()), err.Error())
+				}
+
+				continue
+			}
+
+			for _, fs := range embedded {
+				if names[fs.Name] {
+					continue
 				}
+
+				names[fs.Name] = true
+				fields = append(fields, fs)
+			}
+
+			continue
+		}
+
+		fs, err := parseField(v, tag, qf)
+
+		if err != nil {
+			errs.Add(fset.Position(v.Pos()), err.Error())
+			continue
+		}
+
+		if strings.Contains(fs.Type, "invalid type") {
+			fs.Type = sourceType(expr, fs.Name)
+		}
+
+		fields = append(fields, fs)
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	return fields, nil
+}
+
+// hasPrimaryKey reports whether the struct has a field named ID or tagged with `gorm:"primary_key"` including
+// the promoted ones, by which the models are told from the helper structs. Unresolved embedded structs such as
+// gorm.Model are assumed to have it.
+func hasPrimaryKey(st *types.Struct, visited map[types.Type]bool) bool {
+	for i := 0; i < st.NumFields(); i++ {
+		v, tag := st.Field(i), st.Tag(i)
+
+		if isEmbeddedStruct(v, tag) {
+			t := v.Type()
+
+			if p, ok := t.(*types.Pointer); ok {
+				t = p.Elem()
+			}
+
+			est, ok := t.Underlying().(*types.Struct)
+			if !ok {
+				return true
+			}
+
+			if visited[t] {
+				continue
+			}
+
+			visited[t] = true
+
+			if hasPrimaryKey(est, visited) {
+				return true
 			}
+
+			continue
+		}
+
+		if _, ok := (&Field{Tag: tag}).GormSetting("primary_key"); ok || v.Name() == "ID" {
+			return true
 		}
+	}
+
+	return false
+}
 
+// isEmbeddedStruct reports whether the fields of the embedded field are promoted to its parent
+// like encoding/json does, i.e. it is an embedded struct (or an unresolved type) without JSON name.
+func isEmbeddedStruct(v *types.Var, tag string) bool {
+	if !v.Anonymous() {
+		return false
+	}
+
+	if strings.Split(reflect.StructTag(tag).Get("json"), ",")[0] != "" {
+		return false
+	}
+
+	t := v.Type()
+
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+
+	switch u := t.Underlying().(type) {
+	case *types.Struct:
 		return true
-	})
+	case *types.Basic:
+		return u.Kind() == types.Invalid
+	}
+
+	return false
+}
+
+func parseEmbedded(fset *token.FileSet, v *types.Var, expr ast.Expr, qf types.Qualifier, visited map[types.Type]bool) ([]*Field, error) {
+	t := v.Type()
+
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+
+	if st, ok := t.Underlying().(*types.Struct); ok {
+		if visited[t] {
+			return []*Field{}, nil
+		}
+
+		visited[t] = true
+		defer delete(visited, t)
+
+		return parseStruct(fset, st, nil, qf, visited)
+	}
+
+	source := sourceType(expr, v.Name())
+
+	if fields, ok := knownEmbeddedFields(strings.TrimPrefix(source, "*")); ok {
+		return fields, nil
+	}
+
+	return nil, fmt.Errorf("cannot resolve embedded struct %s, please make sure its package is installed", source)
+}
+
+// elemType returns the type of the elements which the type holds, e.g. Company for []*Company
+func elemType(t types.Type) types.Type {
+	for {
+		switch x := t.(type) {
+		case *types.Pointer:
+			t = x.Elem()
+		case *types.Slice:
+			t = x.Elem()
+		case *types.Array:
+			t = x.Elem()
+		case *types.Map:
+			t = x.Elem()
+		default:
+			return t
+		}
+	}
+}
+
+// supportedType reports whether the field type can be handled by generated code.
+// Invalid types are allowed because they come from packages which cannot be loaded.
+func supportedType(t types.Type) bool {
+	switch x := t.(type) {
+	case *types.Basic:
+		return x.Kind() != types.UnsafePointer
+	case *types.Named:
+		if _, ok := x.Underlying().(*types.Struct); ok {
+			return true
+		}
+
+		return supportedType(x.Underlying())
+	case *types.Pointer:
+		return supportedType(x.Elem())
+	case *types.Slice:
+		return supportedType(x.Elem())
+	case *types.Array:
+		return supportedType(x.Elem())
+	case *types.Map:
+		return supportedType(x.Key()) && supportedType(x.Elem())
+	case *types.Interface:
+		// values of interface{} are decoded from JSON, e.g. map[string]interface{}
+		return x.Empty()
+	}
+
+	// aliases such as json.RawMessage in recent Go are resolved to the types they denote
+	if u := t.Underlying(); u != t {
+		if _, ok := u.(*types.Struct); ok {
+			return true
+		}
+
+		return supportedType(u)
+	}
+
+	return false
+}
+
+func identNames(idents []*ast.Ident) string {
+	names := make([]string, 0, len(idents))
+
+	for _, ident := range idents {
+		names = append(names, ident.Name)
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// sourceType returns the type expression of the named field as written in the source.
+func sourceType(expr ast.Expr, name string) string {
+	x, ok := expr.(*ast.StructType)
+	if !ok {
+		return ""
+	}
+
+	for _, field := range x.Fields.List {
+		if len(field.Names) == 0 && embeddedName(field.Type) == name {
+			return types.ExprString(field.Type)
+		}
+
+		for _, ident := range field.Names {
+			if ident.Name == name {
+				return types.ExprString(field.Type)
+			}
+		}
+	}
+
+	return ""
+}
+
+// embeddedName returns the implicit field name of the embedded field, e.g. *gorm.Model -> Model
+func embeddedName(expr ast.Expr) string {
+	switch x := expr.(type) {
+	case *ast.Ident:
+		return x.Name
+	case *ast.SelectorExpr:
+		return x.Sel.Name
+	case *ast.StarExpr:
+		return embeddedName(x.X)
+	}
 
-	return models, nil
+	return ""
 }
 
 func parseImport(path string) ([]string, error) {
@@ -192,7 +727,7 @@ func parseNamespace(path string) (string, error) {
 
 				for _, expr := range assign.Rhs {
 					call, ok := expr.(*ast.CallExpr)
-					if !ok {
+					if !ok || !isEngineCall(fn, call) {
 						continue
 					}
 
@@ -216,3 +751,20 @@ func parseNamespace(path string) (string, error) {
 
 	return namespace, nil
 }
+
+// isEngineCall reports whether the function of the call is a method of the engine given to fn, e.g. r.Group,
+// which distinguishes the namespace from the route groups of model packages, e.g. api.Group("/billing").
+func isEngineCall(fn *ast.FuncDecl, call *ast.CallExpr) bool {
+	params := fn.Type.Params.List
+	if len(params) == 0 || len(params[0].Names) == 0 {
+		return false
+	}
+
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+
+	ident, ok := sel.X.(*ast.Ident)
+	return ok && ident.Name == params[0].Names[0].Name
+}
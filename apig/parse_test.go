@@ -1,7 +1,9 @@
 package apig
 
 import (
+	"go/scanner"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -24,7 +26,7 @@ func fieldEquals(f1, f2 *Field) bool {
 func TestParseModel(t *testing.T) {
 	path := filepath.Join("testdata", "parse", "models.go")
 
-	models, err := parseModel(path)
+	models, err := parseModel([]string{path})
 
 	if err != nil {
 		t.Fatalf("Failed to parse model file. error: %s", err)
@@ -104,3 +106,338 @@ func TestParseNamespace(t *testing.T) {
 		t.Fatalf("Incorrect namespace. expected: %s, actual: %s", expected, namespace)
 	}
 }
+
+func TestParseModelPackage(t *testing.T) {
+	paths := []string{
+		filepath.Join("testdata", "models", "profile.go"),
+		filepath.Join("testdata", "models", "user.go"),
+	}
+
+	models, err := parseModel(paths)
+
+	if err != nil {
+		t.Fatalf("Failed to parse model files. error: %s", err)
+	}
+
+	if len(models) != 2 {
+		t.Fatalf("Number of parsed models is incorrect. expected: 2, actual: %d", len(models))
+	}
+
+	user := models[1]
+
+	if user.Name != "User" {
+		t.Fatalf("Incorrect model name. expected: User, actual: %s", user.Name)
+	}
+
+	expectedFields := []*Field{
+		&Field{
+			Name:     "ID",
+			JSONName: "id",
+			Type:     "uint",
+		},
+		&Field{
+			Name:     "Name",
+			JSONName: "name",
+			Type:     "string",
+		},
+		&Field{
+			Name:     "Status",
+			JSONName: "status",
+			Type:     "Status",
+		},
+		&Field{
+			Name:     "Profile",
+			JSONName: "profile",
+			Type:     "*Profile",
+		},
+		&Field{
+			Name:     "CreatedAt",
+			JSONName: "created_at",
+			Type:     "*time.Time",
+		},
+	}
+
+	if len(user.Fields) != len(expectedFields) {
+		t.Fatalf("Number of parsed fields is incorrect. expected: %d, actual: %d", len(expectedFields), len(user.Fields))
+	}
+
+	for i, actual := range user.Fields {
+		if !fieldEquals(expectedFields[i], actual) {
+			t.Fatalf("Incorrect field. expected: %#v, actual: %#v", expectedFields[i], actual)
+		}
+	}
+
+	if basicType := user.Fields[2].BasicType(); basicType != "string" {
+		t.Fatalf("Incorrect basic type. expected: string, actual: %s", basicType)
+	}
+}
+
+func TestParseModelEmbedded(t *testing.T) {
+	path := filepath.Join("testdata", "embedded", "models.go")
+
+	models, err := parseModel([]string{path})
+
+	if err != nil {
+		t.Fatalf("Failed to parse model file. error: %s", err)
+	}
+
+	// Timestamps is only embedded in Comment
+	if len(models) != 2 || models[0].Name != "Article" || models[1].Name != "Comment" {
+		t.Fatalf("Incorrect parsed models. expected: [Article Comment], actual: %v", modelNames(models))
+	}
+
+	cases := []struct {
+		model  *Model
+		fields []*Field
+	}{
+		{
+			model: models[0],
+			fields: []*Field{
+				&Field{
+					Name:     "ID",
+					JSONName: "ID",
+					Type:     "uint",
+				},
+				&Field{
+					Name:     "CreatedAt",
+					JSONName: "CreatedAt",
+					Type:     "time.Time",
+				},
+				&Field{
+					Name:     "UpdatedAt",
+					JSONName: "UpdatedAt",
+					Type:     "time.Time",
+				},
+				&Field{
+					Name:     "DeletedAt",
+					JSONName: "DeletedAt",
+					Type:     "*time.Time",
+				},
+				&Field{
+					Name:     "Title",
+					JSONName: "title",
+					Type:     "string",
+				},
+			},
+		},
+		{
+			model: models[1],
+			fields: []*Field{
+				&Field{
+					Name:     "ID",
+					JSONName: "id",
+					Type:     "uint",
+				},
+				&Field{
+					Name:     "CreatedAt",
+					JSONName: "created_at",
+					Type:     "*time.Time",
+				},
+				&Field{
+					Name:     "Body",
+					JSONName: "body",
+					Type:     "string",
+				},
+				&Field{
+					Name:     "UpdatedAt",
+					JSONName: "modified_at",
+					Type:     "*time.Time",
+				},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		if len(c.model.Fields) != len(c.fields) {
+			t.Fatalf("Number of parsed fields of %s is incorrect. expected: %d, actual: %d", c.model.Name, len(c.fields), len(c.model.Fields))
+		}
+
+		for i, actual := range c.model.Fields {
+			if !fieldEquals(c.fields[i], actual) {
+				t.Fatalf("Incorrect field. expected: %#v, actual: %#v", c.fields[i], actual)
+			}
+		}
+	}
+}
+
+func TestParseModelInvalid(t *testing.T) {
+	path := filepath.Join("testdata", "invalid", "models.go")
+
+	_, err := parseModel([]string{path})
+
+	if err == nil {
+		t.Fatal("Error should be raised.")
+	}
+
+	errs, ok := err.(scanner.ErrorList)
+	if !ok {
+		t.Fatalf("Error should be scanner.ErrorList. actual: %#v", err)
+	}
+
+	expected := []int{5, 6, 7}
+
+	if len(errs) != len(expected) {
+		t.Fatalf("Number of errors is incorrect. expected: %d, actual: %d\n%s", len(expected), len(errs), err)
+	}
+
+	for i, e := range errs {
+		if e.Pos.Filename != path || e.Pos.Line != expected[i] {
+			t.Fatalf("Incorrect error position. expected: %s:%d, actual: %s", path, expected[i], e.Pos)
+		}
+	}
+}
+
+func TestParseModelHelper(t *testing.T) {
+	path := filepath.Join("testdata", "helper", "models.go")
+
+	models, warnings, err := parsePackages(map[string][]string{"": []string{path}}, "")
+
+	if err != nil {
+		t.Fatalf("Error should not be raised for the helper struct. error: %s", err)
+	}
+
+	if len(models) != 1 || models[0].Name != "User" {
+		t.Fatalf("Incorrect parsed models. expected: [User], actual: %v", modelNames(models))
+	}
+
+	if len(warnings) != 1 || !strings.Contains(warnings[0], path+":9:") || !strings.Contains(warnings[0], "struct Callbacks is skipped") {
+		t.Fatalf("Helper struct should be skipped with the warning. actual: %v", warnings)
+	}
+}
+
+func TestParseModelDirectives(t *testing.T) {
+	path := filepath.Join("testdata", "directives", "models.go")
+
+	models, err := parseModel([]string{path})
+
+	if err != nil {
+		t.Fatalf("Failed to parse model file. error: %s", err)
+	}
+
+	if len(models) != 3 {
+		t.Fatalf("Number of parsed models is incorrect. expected: 3, actual: %d", len(models))
+	}
+
+	cases := []struct {
+		skip     bool
+		readOnly bool
+		resource string
+	}{
+		{true, false, "audits"},
+		{false, true, "people"},
+		{false, false, "teams"},
+	}
+
+	for i, c := range cases {
+		m := models[i]
+
+		if m.Skip != c.skip || m.ReadOnly != c.readOnly || m.Resource() != c.resource {
+			t.Fatalf("Incorrect directives of %s. expected: %v, actual: {%v %v %s}", m.Name, c, m.Skip, m.ReadOnly, m.Resource())
+		}
+	}
+}
+
+func TestParseModelInvalidDirectives(t *testing.T) {
+	path := filepath.Join("testdata", "invalid", "directives.go")
+
+	_, err := parseModel([]string{path})
+
+	if err == nil {
+		t.Fatal("Error should be raised.")
+	}
+
+	errs, ok := err.(scanner.ErrorList)
+	if !ok {
+		t.Fatalf("Error should be scanner.ErrorList. actual: %#v", err)
+	}
+
+	expected := []int{3, 9}
+
+	if len(errs) != len(expected) {
+		t.Fatalf("Number of errors is incorrect. expected: %d, actual: %d\n%s", len(expected), len(errs), err)
+	}
+
+	for i, e := range errs {
+		if e.Pos.Filename != path || e.Pos.Line != expected[i] {
+			t.Fatalf("Incorrect error position. expected: %s:%d, actual: %s", path, expected[i], e.Pos)
+		}
+	}
+}
+
+func TestParseOptions(t *testing.T) {
+	field := &Field{Name: "Password"}
+
+	if err := parseOptions(field, "writeonly,required"); err != nil {
+		t.Fatalf("Error should not be raised: %s", err)
+	}
+
+	if !field.WriteOnly || !field.Required || field.Hidden || field.ReadOnly || field.Filterable || field.Sortable {
+		t.Fatalf("Incorrect options. actual: %#v", field)
+	}
+
+	if field.IsReadable() || !field.IsWritable() {
+		t.Fatalf("Password should be writable but not readable.")
+	}
+
+	for _, options := range []string{"private", "readonly,writeonly"} {
+		if err := parseOptions(&Field{Name: "Password"}, options); err == nil {
+			t.Fatalf("Error should be raised: %s", options)
+		}
+	}
+}
+
+func TestParseModelJSON(t *testing.T) {
+	path := filepath.Join("testdata", "json", "models.go")
+
+	models, err := parseModel([]string{path})
+
+	if err != nil {
+		t.Fatalf("Failed to parse model file. error: %s", err)
+	}
+
+	if len(models) != 1 {
+		t.Fatalf("Number of parsed models is incorrect. expected: 1, actual: %d", len(models))
+	}
+
+	cases := []struct {
+		name string
+		json bool
+		elem string
+	}{
+		{"Metadata", true, ""},
+		{"Settings", true, ""},
+		{"Raw", true, ""},
+		{"Labels", false, "string"},
+		{"Scores", false, "int"},
+		{"Extra", true, ""},
+	}
+
+	fields := make(map[string]*Field)
+
+	for _, field := range models[0].Fields {
+		fields[field.Name] = field
+	}
+
+	for _, c := range cases {
+		field, ok := fields[c.name]
+		if !ok {
+			t.Fatalf("Field %s should be parsed.", c.name)
+		}
+
+		elem, _ := field.ElemType()
+
+		if field.IsJSON() != c.json || (elem == nil) != (c.elem == "") || (elem != nil && elem.Type != c.elem) {
+			t.Fatalf("Incorrect type of %s. expected: %v, actual: %s", c.name, c, field.Type)
+		}
+	}
+}
+
+func modelNames(models []*Model) []string {
+	names := []string{}
+
+	for _, m := range models {
+		names = append(names, m.Name)
+	}
+
+	return names
+}
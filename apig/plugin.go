@@ -0,0 +1,152 @@
+package apig
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// Generator generates files from the models of the project in addition to the built-in generators,
+// e.g. audit tables or admin pages.
+type Generator interface {
+	// Generate renders the files from detail, which has all the models with their associations resolved,
+	// and writes them by w.
+	Generate(detail *Detail, w *FileWriter) error
+}
+
+// GeneratorFunc is a function used as Generator.
+type GeneratorFunc func(detail *Detail, w *FileWriter) error
+
+func (f GeneratorFunc) Generate(detail *Detail, w *FileWriter) error {
+	return f(detail, w)
+}
+
+var (
+	registeredGenerators = make(map[string]Generator)
+	generatorsMu         sync.Mutex
+)
+
+// Register registers the generator run by every Generate, e.g. in init of the package imported by a custom build
+// of apig. It panics if the name is already registered.
+func Register(name string, g Generator) {
+	generatorsMu.Lock()
+	defer generatorsMu.Unlock()
+
+	if _, ok := registeredGenerators[name]; ok {
+		panic("apig: generator " + name + " is already registered")
+	}
+
+	registeredGenerators[name] = g
+}
+
+// FileWriter writes the files of a generator through the same pipeline as the built-in generators, i.e. the files
+// are not written again if they are unchanged, the edits by hand are merged and they are reported in the result.
+type FileWriter struct {
+	name   string
+	detail *Detail
+	outDir string
+}
+
+// WriteFile writes the content to the path relative to the project. Go files are formatted.
+func (w *FileWriter) WriteFile(p string, content []byte) error {
+	dstPath, err := w.dstPath(p)
+	if err != nil {
+		return err
+	}
+
+	if err := w.detail.manifest.claim(dstPath, w.name); err != nil {
+		return err
+	}
+
+	inputs := inputsHash(append([]byte(w.name+"\x00"), content...), w.detail, nil)
+
+	if w.detail.manifest.unchanged(dstPath, inputs) {
+		return nil
+	}
+
+	src, err := w.format(p, content)
+	if err != nil {
+		return err
+	}
+
+	return writeFile(w.detail.manifest, dstPath, inputs, src)
+}
+
+// ExecuteTemplate renders the template text with data and writes it to the path relative to the project.
+// The functions of the built-in templates are available in the template.
+func (w *FileWriter) ExecuteTemplate(p, text string, data interface{}) error {
+	tmpl, err := template.New(p).Funcs(w.detail.funcs).Parse(text)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return err
+	}
+
+	return w.WriteFile(p, buf.Bytes())
+}
+
+func (w *FileWriter) dstPath(p string) (string, error) {
+	p = path.Clean(filepath.ToSlash(p))
+
+	if path.IsAbs(p) || p == "." || p == ".." || strings.HasPrefix(p, "../") {
+		return "", fmt.Errorf("%s is not in the project", p)
+	}
+
+	return filepath.Join(w.outDir, filepath.FromSlash(p)), nil
+}
+
+func (w *FileWriter) format(p string, src []byte) ([]byte, error) {
+	if !strings.HasSuffix(p, ".go") {
+		return src, nil
+	}
+
+	formatted, err := format.Source(src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to format %s: %s", p, err)
+	}
+
+	return formatted, nil
+}
+
+// runGenerators runs the registered generators and the given ones in the order of their names.
+func runGenerators(detail *Detail, outDir string, generators map[string]Generator) error {
+	all := make(map[string]Generator)
+
+	generatorsMu.Lock()
+	for name, g := range registeredGenerators {
+		all[name] = g
+	}
+	generatorsMu.Unlock()
+
+	for name, g := range generators {
+		if _, ok := all[name]; ok {
+			return fmt.Errorf("generator %s is already registered", name)
+		}
+
+		all[name] = g
+	}
+
+	names := make([]string, 0, len(all))
+	for name := range all {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := all[name].Generate(detail, &FileWriter{name: name, detail: detail, outDir: outDir}); err != nil {
+			return fmt.Errorf("generator %s: %s", name, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package apig
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/serenize/snaker"
+)
+
+const auditTemplate = `package audit
+
+type {{ .Name }}Audit struct {
+	ID uint
+	{{ .Name }}ID uint
+	Action string
+}
+`
+
+func TestGenerate_Generators(t *testing.T) {
+	audit := GeneratorFunc(func(detail *Detail, w *FileWriter) error {
+		for _, model := range detail.Models {
+			if err := w.ExecuteTemplate("audit/"+snaker.CamelToSnake(model.Name)+".go", auditTemplate, model); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	result, err := Generate(&Options{
+		OutDir:     filepath.Join("testdata", "legacy"),
+		DryRun:     true,
+		Generators: map[string]Generator{"audit": audit},
+	})
+	if err != nil {
+		t.Fatalf("Error should not be raised: %s", err)
+	}
+
+	var file *File
+
+	for _, f := range result.Files {
+		if f.Path == "audit/user.go" {
+			file = f
+		}
+	}
+
+	if file == nil || file.Status != StatusCreate {
+		t.Fatalf("File of the generator should be created. actual: %#v", file)
+	}
+
+	if !strings.Contains(string(file.Content), "\tUserID uint\n") {
+		t.Fatalf("File of the generator should be rendered and formatted. actual:\n%s", file.Content)
+	}
+
+	escape := GeneratorFunc(func(detail *Detail, w *FileWriter) error {
+		return w.WriteFile("../escape.go", []byte("package main\n"))
+	})
+
+	if _, err := Generate(&Options{
+		OutDir:     filepath.Join("testdata", "legacy"),
+		DryRun:     true,
+		Generators: map[string]Generator{"escape": escape},
+	}); err == nil {
+		t.Fatalf("Error should be raised when the file is out of the project.")
+	}
+
+	readme := GeneratorFunc(func(detail *Detail, w *FileWriter) error {
+		return w.WriteFile("README.md", []byte("# audit\n"))
+	})
+
+	cases := []struct {
+		generators map[string]Generator
+		expected   string
+	}{
+		{map[string]Generator{"audit": audit, "copy": audit}, "audit/user.go is written by both generators audit and copy"},
+		{map[string]Generator{"readme": readme}, "README.md is written by both generators apig and readme"},
+	}
+
+	for _, c := range cases {
+		_, err := Generate(&Options{
+			OutDir:     filepath.Join("testdata", "legacy"),
+			DryRun:     true,
+			Generators: c.generators,
+		})
+
+		if err == nil || !strings.Contains(err.Error(), c.expected) {
+			t.Fatalf("Error should be raised when the file is written by two generators. expected: %s, actual: %v", c.expected, err)
+		}
+	}
+}
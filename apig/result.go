@@ -0,0 +1,91 @@
+package apig
+
+import (
+	"sort"
+)
+
+// FileStatus is what the generation did, or would do in the dry run, to the file.
+type FileStatus string
+
+const (
+	StatusCreate    FileStatus = "create"
+	StatusUpdate    FileStatus = "update"
+	StatusMerge     FileStatus = "merge"
+	StatusIdentical FileStatus = "identical"
+	StatusKeep      FileStatus = "keep"
+	StatusConflict  FileStatus = "conflict"
+)
+
+// File is a file generated by Generate or Skeleton.
+type File struct {
+	// Path is the slash-separated path relative to the output directory, e.g. controllers/user.go
+	Path   string
+	Status FileStatus
+	// Reason describes the status, e.g. why the file conflicts.
+	Reason string
+	// Content is the content of the file after the generation. For the conflict, it is the generated content
+	// which is not written.
+	Content []byte
+	// Previous is the content of the file before the generation, or nil if it did not exist.
+	Previous []byte
+}
+
+// Changed reports whether the file is out of date, i.e. changed by the generation or conflicts with it.
+func (f *File) Changed() bool {
+	return f.Status != StatusIdentical && f.Status != StatusKeep
+}
+
+// Diff returns the changes of the file by the generation in the unified format.
+func (f *File) Diff() string {
+	if f.Status == StatusConflict {
+		return ""
+	}
+
+	fromPath := "a/" + f.Path
+
+	if f.Previous == nil {
+		fromPath = "/dev/null"
+	}
+
+	return unifiedDiff(fromPath, "b/"+f.Path, string(f.Previous), string(f.Content))
+}
+
+// Result is the result of Generate or Skeleton.
+type Result struct {
+	// Files are all the files of the generation sorted by their paths.
+	Files []*File
+	// Warnings are the problems of the models which do not stop the generation.
+	Warnings []string
+}
+
+// Changed returns the files out of date.
+func (r *Result) Changed() []*File {
+	var files []*File
+
+	for _, f := range r.Files {
+		if f.Changed() {
+			files = append(files, f)
+		}
+	}
+
+	return files
+}
+
+// Conflicts returns the files which are not generated because of the conflicts with the edits by hand.
+func (r *Result) Conflicts() []*File {
+	var files []*File
+
+	for _, f := range r.Files {
+		if f.Status == StatusConflict {
+			files = append(files, f)
+		}
+	}
+
+	return files
+}
+
+func sortFiles(files []*File) {
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Path < files[j].Path
+	})
+}
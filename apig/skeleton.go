@@ -4,110 +4,164 @@ import (
 	"bytes"
 	"fmt"
 	"go/format"
-	"io/ioutil"
-	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
-	"sync"
 	"text/template"
 
-	"github.com/wantedly/apig/msg"
 	"github.com/wantedly/apig/util"
 )
 
 var r = regexp.MustCompile(`_templates/skeleton/.*\.tmpl$`)
 
-func generateSkeleton(detail *Detail, outDir string) error {
-	var wg sync.WaitGroup
-	errCh := make(chan error, 1)
-	done := make(chan bool, 1)
+// regeneratedFiles are the files of the skeleton which are generated again from the models by Generate, so they are
+// skipped by gen -all not to be generated twice.
+var regeneratedFiles = map[string]bool{
+	"README.md":        true,
+	"db/db.go":         true,
+	"router/router.go": true,
+}
+
+// generateSkeleton renders the skeleton templates except the files in skip.
+func generateSkeleton(detail *Detail, outDir string, skip map[string]bool) error {
+	var tasks []func() error
 
 	for _, skeleton := range AssetNames() {
-		wg.Add(1)
-		go func(s string) {
-			defer wg.Done()
+		if !r.MatchString(skeleton) {
+			continue
+		}
 
-			if !r.MatchString(s) {
-				return
-			}
+		s := skeleton
+		trim := strings.Replace(s, "_templates/skeleton/", "", 1)
+		path := strings.Replace(trim, ".tmpl", "", 1)
 
-			trim := strings.Replace(s, "_templates/skeleton/", "", 1)
-			path := strings.Replace(trim, ".tmpl", "", 1)
-			dstPath := filepath.Join(outDir, path)
+		if skip[path] {
+			continue
+		}
 
-			body, err := Asset(s)
-			if err != nil {
-				errCh <- err
+		tasks = append(tasks, func() error {
+			if err := generateSkeletonFile(detail, outDir, s, path); err != nil {
+				return fmt.Errorf("%s: %s", path, err)
 			}
 
-			tmpl, err := template.New("complex").Parse(string(body))
-			if err != nil {
-				errCh <- err
-			}
+			return nil
+		})
+	}
 
-			var buf bytes.Buffer
-			var src []byte
+	return runTasks(tasks)
+}
 
-			if err := tmpl.Execute(&buf, detail); err != nil {
-				errCh <- err
-			}
+// generateSkeletonFile renders the skeleton template s, e.g. _templates/skeleton/main.go.tmpl, to path in outDir.
+func generateSkeletonFile(detail *Detail, outDir, s, path string) error {
+	dstPath := filepath.Join(outDir, path)
 
-			if strings.HasSuffix(path, ".go") {
-				src, err = format.Source(buf.Bytes())
-				if err != nil {
-					errCh <- err
-				}
-			} else {
-				src = buf.Bytes()
-			}
+	body, err := readTemplate(detail.templates, strings.TrimPrefix(s, templateDir+"/"))
+	if err != nil {
+		return err
+	}
 
-			if !util.FileExists(filepath.Dir(dstPath)) {
-				if err := util.Mkdir(filepath.Dir(dstPath)); err != nil {
-					errCh <- err
-				}
-			}
+	inputs := inputsHash(body, detail, nil)
 
-			if err := ioutil.WriteFile(dstPath, src, 0644); err != nil {
-				errCh <- err
-			}
+	if detail.manifest.unchanged(dstPath, inputs) {
+		return nil
+	}
 
-			msg.Printf("\t\x1b[32m%s\x1b[0m %s\n", "create", dstPath)
-		}(skeleton)
+	tmpl, err := template.New("complex").Funcs(detail.funcs).Parse(string(body))
+	if err != nil {
+		return err
 	}
 
-	wg.Wait()
-	close(done)
+	var buf bytes.Buffer
+	var src []byte
+
+	if err := tmpl.Execute(&buf, detail); err != nil {
+		return err
+	}
 
-	select {
-	case <-done:
-	case err := <-errCh:
+	if strings.HasSuffix(path, ".go") {
+		src, err = format.Source(buf.Bytes())
 		if err != nil {
 			return err
 		}
+	} else {
+		src = buf.Bytes()
 	}
 
-	return nil
+	return writeFile(detail.manifest, dstPath, inputs, src)
 }
 
-func Skeleton(gopath, vcs, username, project, namespace, database string) int {
+// SkeletonOptions is the options of Skeleton.
+type SkeletonOptions struct {
+	// OutDir is the directory of the project, which must not exist yet.
+	OutDir string
+	// ImportPath is the module path of the project, e.g. github.com/wantedly/api-server
+	ImportPath string
+	// Namespace is the path prefix of the API, e.g. api
+	Namespace string
+	// Database is the database engine, i.e. sqlite, postgres or mysql
+	Database string
+	// Templates is the directory of the templates overriding the embedded ones.
+	Templates string
+	// DryRun renders the files in memory without writing anything. Their contents are in the result.
+	DryRun bool
+	// Funcs are available in the templates in addition to the built-in functions and the ones registered by
+	// RegisterFunc.
+	Funcs template.FuncMap
+	// FuncsVersion is the version of Funcs and the functions registered by RegisterFunc. Only the names of them are
+	// known to the generation otherwise, so change it when they are changed to generate the files again.
+	FuncsVersion string
+}
+
+// Skeleton generates the boilerplate of the project as a Go module.
+func Skeleton(opts *SkeletonOptions) (*Result, error) {
+	if opts.Templates != "" && !util.FileExists(opts.Templates) {
+		return nil, fmt.Errorf("%s is not found", opts.Templates)
+	}
+
+	if util.FileExists(opts.OutDir) {
+		return nil, fmt.Errorf("%s is already exists", opts.OutDir)
+	}
+
+	funcs, err := newFuncMap(opts.Funcs, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	vcs, user, project := splitImportPath(opts.ImportPath)
+
+	manifest, err := loadManifest(opts.OutDir)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest.dryRun = opts.DryRun
+
 	detail := &Detail{
-		VCS:       vcs,
-		User:      username,
-		Project:   project,
-		Namespace: namespace,
-		Database:  database,
+		VCS:          vcs,
+		User:         user,
+		Project:      project,
+		ImportDir:    opts.ImportPath,
+		Namespace:    opts.Namespace,
+		Database:     opts.Database,
+		manifest:     manifest,
+		templates:    opts.Templates,
+		funcs:        funcs,
+		funcsVersion: opts.FuncsVersion,
 	}
-	outDir := filepath.Join(gopath, "src", detail.VCS, detail.User, detail.Project)
-	if util.FileExists(outDir) {
-		fmt.Fprintf(os.Stderr, "%s is already exists", outDir)
-		return 1
+
+	err = generateSkeleton(detail, opts.OutDir, nil)
+	if err == nil {
+		err = generateGoMod(detail, opts.OutDir)
+	}
+
+	// the files written before the failure are recorded as well, as their bases of the merge are already updated
+	if serr := manifest.save(); err == nil {
+		err = serr
 	}
-	if err := generateSkeleton(detail, outDir); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		return 1
+
+	if err != nil {
+		return nil, err
 	}
 
-	msg.Printf("===> Created %s", outDir)
-	return 0
+	return &Result{Files: manifest.result()}, nil
 }
@@ -16,7 +16,7 @@ func TestGenerateSkeleton(t *testing.T) {
 
 	outDir := filepath.Join(tempDir, "api-server")
 
-	if err := generateSkeleton(detail, outDir); err != nil {
+	if err := generateSkeleton(detail, outDir, nil); err != nil {
 		t.Fatalf("Error should not be raised: %s", err)
 	}
 
@@ -24,6 +24,7 @@ func TestGenerateSkeleton(t *testing.T) {
 		"README.md",
 		".gitignore",
 		"main.go",
+		"apig.yml",
 		filepath.Join("db", "db.go"),
 		filepath.Join("db", "pagination.go"),
 		filepath.Join("router", "router.go"),
@@ -0,0 +1,82 @@
+package apig
+
+import (
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// maxWorkers is the number of the files rendered at once.
+var maxWorkers = runtime.NumCPU()
+
+// Errors is the failures of the generation, e.g. the templates which failed to render.
+type Errors []error
+
+func (e Errors) Error() string {
+	messages := make([]string, 0, len(e))
+
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+
+	return strings.Join(messages, "\n")
+}
+
+// runTasks runs the tasks concurrently by at most maxWorkers goroutines. The tasks which have not started yet are
+// skipped after the first failure, and the errors of all the failed tasks are returned together as Errors.
+func runTasks(tasks []func() error) error {
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		once   sync.Once
+		errs   Errors
+		sem    = make(chan struct{}, maxWorkers)
+		failed = make(chan struct{})
+	)
+
+loop:
+	for _, task := range tasks {
+		// checked first as select chooses randomly when a worker is free as well
+		select {
+		case <-failed:
+			break loop
+		default:
+		}
+
+		select {
+		case <-failed:
+			break loop
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(task func() error) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			if err := task(); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+
+				once.Do(func() { close(failed) })
+			}
+		}(task)
+	}
+
+	wg.Wait()
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	// the order of the goroutines does not matter to the report
+	sort.Slice(errs, func(i, j int) bool {
+		return errs[i].Error() < errs[j].Error()
+	})
+
+	return errs
+}
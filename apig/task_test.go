@@ -0,0 +1,109 @@
+package apig
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunTasks(t *testing.T) {
+	defer func(n int) { maxWorkers = n }(maxWorkers)
+	maxWorkers = 2
+
+	var running, peak int32
+
+	tasks := []func() error{}
+
+	for i := 0; i < 10; i++ {
+		tasks = append(tasks, func() error {
+			n := atomic.AddInt32(&running, 1)
+			defer atomic.AddInt32(&running, -1)
+
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+
+			time.Sleep(time.Millisecond)
+
+			return nil
+		})
+	}
+
+	if err := runTasks(tasks); err != nil {
+		t.Fatalf("Error should not be raised: %s", err)
+	}
+
+	if peak > 2 {
+		t.Fatalf("Incorrect number of the tasks run at once. expected: <= 2, actual: %d", peak)
+	}
+
+	// both tasks fail as the first one waits for the second one, and the third one is skipped
+	second := make(chan struct{})
+	var run int32
+
+	err := runTasks([]func() error{
+		func() error {
+			<-second
+			return errors.New("b failed")
+		},
+		func() error {
+			close(second)
+			return errors.New("a failed")
+		},
+		func() error { atomic.AddInt32(&run, 1); return nil },
+	})
+
+	errs, ok := err.(Errors)
+	if !ok || len(errs) != 2 || errs.Error() != "a failed\nb failed" {
+		t.Fatalf("Errors of all the failed tasks should be returned. actual: %#v", err)
+	}
+
+	if run != 0 {
+		t.Fatalf("Tasks should be skipped after the failure. run: %d", run)
+	}
+
+	maxWorkers = 1
+
+	err = runTasks([]func() error{
+		func() error { return errors.New("failed") },
+		func() error { atomic.AddInt32(&run, 1); return nil },
+	})
+
+	if err == nil || run != 0 {
+		t.Fatalf("Tasks should be skipped after the failure. error: %v, run: %d", err, run)
+	}
+}
+
+func TestGenerateCommonFiles_Errors(t *testing.T) {
+	templates, err := ioutil.TempDir("", "templates")
+	if err != nil {
+		t.Fatal("Failed to create tempdir")
+	}
+	defer os.RemoveAll(templates)
+
+	if err := ioutil.WriteFile(filepath.Join(templates, "controller.go.tmpl"), []byte("{{ .Model.Unknown }}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	models := Models{}
+
+	for i := 0; i < 2*maxWorkers+2; i++ {
+		models = append(models, &Model{Name: fmt.Sprintf("Model%d", i)})
+	}
+
+	d := *detail
+	d.Models = models
+	d.templates = templates
+
+	if _, ok := generateCommonFiles(&d, "").(Errors); !ok {
+		t.Fatalf("Errors should be raised when the template fails.")
+	}
+}
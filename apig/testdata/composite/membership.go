@@ -0,0 +1,12 @@
+package models
+
+type User struct {
+	ID   uint   `gorm:"primary_key;AUTO_INCREMENT" json:"id"`
+	Name string `json:"name"`
+}
+
+type Membership struct {
+	UserID    uint   `gorm:"primary_key;auto_increment:false" json:"user_id"`
+	CompanyID uint   `gorm:"primary_key;auto_increment:false" json:"company_id"`
+	Role      string `json:"role"`
+}
@@ -0,0 +1,21 @@
+package models
+
+// Audit is written by the server only.
+// apig:skip
+type Audit struct {
+	ID     uint   `gorm:"primary_key;AUTO_INCREMENT" json:"id"`
+	Action string `json:"action"`
+}
+
+// apig:readonly
+// apig:path=/people
+type Person struct {
+	ID   uint   `gorm:"primary_key;AUTO_INCREMENT" json:"id"`
+	Name string `json:"name"`
+}
+
+type (
+	Team struct {
+		ID uint `gorm:"primary_key;AUTO_INCREMENT" json:"id"`
+	}
+)
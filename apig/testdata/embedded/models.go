@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+type Timestamps struct {
+	CreatedAt *time.Time `json:"created_at,omitempty" form:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty" form:"updated_at"`
+}
+
+type Article struct {
+	gorm.Model
+	Title string `json:"title,omitempty" form:"title"`
+}
+
+type Comment struct {
+	ID uint `gorm:"primary_key;AUTO_INCREMENT" json:"id,omitempty" form:"id"`
+	*Timestamps
+	Body      string     `json:"body,omitempty" form:"body"`
+	UpdatedAt *time.Time `json:"modified_at,omitempty" form:"modified_at"`
+}
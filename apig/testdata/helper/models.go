@@ -0,0 +1,10 @@
+package models
+
+type User struct {
+	ID   uint   `gorm:"primary_key;AUTO_INCREMENT" json:"id,omitempty" form:"id"`
+	Name string `json:"name,omitempty" form:"name"`
+}
+
+type Callbacks struct {
+	OnSave func(*User) error
+}
@@ -0,0 +1,13 @@
+package models
+
+// apig:path=/
+type Team struct {
+	ID uint `gorm:"primary_key;AUTO_INCREMENT" json:"id"`
+}
+
+type (
+	// apig:paginate
+	Project struct {
+		ID uint `gorm:"primary_key;AUTO_INCREMENT" json:"id"`
+	}
+)
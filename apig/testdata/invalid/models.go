@@ -0,0 +1,13 @@
+package models
+
+type User struct {
+	ID         uint   `gorm:"primary_key;AUTO_INCREMENT" json:"id,omitempty" form:"id"`
+	First, Last string `json:"name,omitempty" form:"name"`
+	Email      string `json:"email,omitempty",form:"email"`
+	Events     chan string
+}
+
+type Job struct {
+	ID   uint   `gorm:"primary_key;AUTO_INCREMENT" json:"id,omitempty" form:"id"`
+	Name string `json:"name,omitempty" form:"name"`
+}
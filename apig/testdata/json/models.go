@@ -0,0 +1,32 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+)
+
+type Metadata map[string]interface{}
+
+func (m Metadata) Value() (driver.Value, error) {
+	return json.Marshal(m)
+}
+
+func (m *Metadata) Scan(value interface{}) error {
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	return json.Unmarshal(b, m)
+}
+
+type Plan struct {
+	ID       uint              `gorm:"primary_key;AUTO_INCREMENT" json:"id"`
+	Metadata Metadata          `gorm:"type:text" json:"metadata"`
+	Settings Metadata          `json:"settings"`
+	Raw      json.RawMessage   `json:"raw"`
+	Labels   []string          `sql:"-" json:"labels"`
+	Scores   []int             `json:"scores"`
+	Extra    map[string]string `json:"extra"`
+}
@@ -0,0 +1,15 @@
+package db
+
+import (
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/mysql"
+)
+
+func Connect() *gorm.DB {
+	db, err := gorm.Open("mysql", "root@/api_server")
+	if err != nil {
+		panic(err)
+	}
+
+	return db
+}
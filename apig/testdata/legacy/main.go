@@ -0,0 +1,12 @@
+package main
+
+import (
+	"github.com/wantedly/api-server/db"
+	"github.com/wantedly/api-server/server"
+)
+
+func main() {
+	database := db.Connect()
+	s := server.Setup(database)
+	s.Run(":8080")
+}
@@ -0,0 +1,14 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+func Initialize(r *gin.Engine) {
+	r.GET("/", controllers.APIEndpoints)
+
+	api := r.Group("api")
+	{
+		api.GET("/users", controllers.GetUsers)
+	}
+}
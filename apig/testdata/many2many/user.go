@@ -0,0 +1,13 @@
+package models
+
+type User struct {
+	ID       uint       `gorm:"primary_key;AUTO_INCREMENT" json:"id"`
+	Name     string     `json:"name"`
+	Users    []*User    `gorm:"many2many:user_follows;association_jointable_foreignkey:follow_id" json:"users"`
+	Versions []*Release `gorm:"many2many:user_releases;" json:"versions"`
+}
+
+type Release struct {
+	ID   uint   `gorm:"primary_key;AUTO_INCREMENT" json:"id"`
+	Name string `json:"name"`
+}
@@ -0,0 +1,9 @@
+package models
+
+type Status string
+
+type Profile struct {
+	ID     uint   `gorm:"primary_key;AUTO_INCREMENT" json:"id,omitempty" form:"id"`
+	UserID uint   `json:"user_id,omitempty" form:"user_id"`
+	Bio    string `json:"bio,omitempty" form:"bio"`
+}
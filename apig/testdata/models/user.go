@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+type User struct {
+	ID        uint       `gorm:"primary_key;AUTO_INCREMENT" json:"id,omitempty" form:"id"`
+	Name      string     `json:"name,omitempty" form:"name"`
+	Status    Status     `json:"status,omitempty" form:"status"`
+	Profile   *Profile   `json:"profile,omitempty" form:"profile"`
+	CreatedAt *time.Time `json:"created_at,omitempty" form:"created_at"`
+}
@@ -0,0 +1,9 @@
+package billing
+
+import invoices "github.com/wantedly/api-server/models/billing"
+
+type Plan struct {
+	ID       uint                `gorm:"primary_key;AUTO_INCREMENT" json:"id"`
+	Name     string              `json:"name"`
+	Invoices []*invoices.Invoice `gorm:"many2many:plan_invoices;" json:"invoices"`
+}
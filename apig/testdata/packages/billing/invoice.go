@@ -0,0 +1,10 @@
+package billing
+
+import "github.com/wantedly/api-server/models"
+
+type Invoice struct {
+	ID     uint         `gorm:"primary_key;AUTO_INCREMENT" json:"id"`
+	Amount int          `json:"amount"`
+	UserID uint         `json:"user_id"`
+	User   *models.User `json:"user"`
+}
@@ -0,0 +1,6 @@
+package models
+
+type User struct {
+	ID   uint   `gorm:"primary_key;AUTO_INCREMENT" json:"id"`
+	Name string `json:"name"`
+}
@@ -19,4 +19,12 @@ func Initialize(r *gin.Engine) {
 		api.DELETE("/users/:id", controllers.DeleteUser)
 
 	}
+
+	billing := api.Group("/billing")
+	{
+
+		billing.GET("/invoices", controllers.GetInvoices)
+		billing.GET("/invoices/:id", controllers.GetInvoice)
+
+	}
 }
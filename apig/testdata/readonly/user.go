@@ -0,0 +1,18 @@
+package models
+
+import "github.com/wantedly/api-server/models/tags"
+
+// apig:readonly
+type User struct {
+	ID        uint        `gorm:"primary_key;AUTO_INCREMENT" json:"id"`
+	Name      string      `json:"name"`
+	CompanyID uint        `json:"company_id"`
+	Company   *Company    `json:"company"`
+	Tags      []*tags.Tag `gorm:"many2many:user_tags;" json:"tags"`
+}
+
+type Company struct {
+	ID    uint    `gorm:"primary_key;AUTO_INCREMENT" json:"id"`
+	Name  string  `json:"name"`
+	Users []*User `json:"users"`
+}
@@ -1,11 +1,142 @@
 package apig
 
+import (
+	"errors"
+	"fmt"
+	"go/types"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	errTagSyntax      = errors.New("bad syntax for struct tag pair")
+	errTagKeySyntax   = errors.New("bad syntax for struct tag key")
+	errTagValueSyntax = errors.New("bad syntax for struct tag value")
+	errTagSpace       = errors.New(`key:"value" pairs not separated by spaces`)
+)
+
 func validateForeignKey(fields []*Field, name string) bool {
+	return validateField(fields, name+"ID")
+}
+
+func validateField(fields []*Field, name string) bool {
 	for _, field := range fields {
-		val := name + "ID"
-		if field.Name == val {
+		if field.Name == name {
 			return true
 		}
 	}
 	return false
 }
+
+// validatePolymorphic checks the fields have both <name>ID and <name>Type, e.g. OwnerID and OwnerType
+func validatePolymorphic(fields []*Field, name string) bool {
+	var hasID, hasType bool
+
+	for _, field := range fields {
+		switch field.Name {
+		case name + "ID":
+			hasID = true
+		case name + "Type":
+			hasType = true
+		}
+	}
+
+	return hasID && hasType
+}
+
+// validateStorage checks gorm can store the field as it is. Maps and slices of non-model types must
+// implement driver.Valuer and sql.Scanner, and their column types must be given by the tag,
+// otherwise gorm fails to migrate and write them.
+func validateStorage(field *Field) error {
+	if field.GoType == nil || field.IsAssociation() || reflect.StructTag(field.Tag).Get("sql") == "-" {
+		return nil
+	}
+
+	if _, ok := field.GormSetting("-"); ok {
+		return nil
+	}
+
+	t := field.GoType
+
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+
+	switch x := t.Underlying().(type) {
+	case *types.Map, *types.Interface:
+	case *types.Slice:
+		if b, ok := x.Elem().Underlying().(*types.Basic); ok && b.Kind() == types.Byte {
+			return nil
+		}
+	default:
+		return nil
+	}
+
+	if !hasMethod(t, "Value") {
+		return fmt.Errorf("%s cannot be stored by gorm as it is, please use a type implementing driver.Valuer and sql.Scanner such as postgres.Jsonb, or ignore it with `sql:\"-\"`", field.Type)
+	}
+
+	if _, ok := field.GormSetting("type"); !ok && !hasMethod(t, "GormDataType") && !strings.Contains(strings.ToLower(reflect.StructTag(field.Tag).Get("sql")), "type:") {
+		return fmt.Errorf("column type of %s cannot be inferred by gorm, please specify it with `gorm:\"type:text\"` for example", field.Type)
+	}
+
+	return nil
+}
+
+func hasMethod(t types.Type, name string) bool {
+	obj, _, _ := types.LookupFieldOrMethod(t, true, nil, name)
+	_, ok := obj.(*types.Func)
+	return ok
+}
+
+// validateTag checks the struct tag follows the conventional format `key:"value" key:"value"`,
+// which reflect.StructTag.Get silently ignores otherwise.
+func validateTag(tag string) error {
+	for tag != "" {
+		i := 0
+		for i < len(tag) && tag[i] == ' ' {
+			i++
+		}
+		tag = tag[i:]
+		if tag == "" {
+			break
+		}
+
+		i = 0
+		for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+			i++
+		}
+		if i == 0 {
+			return errTagKeySyntax
+		}
+		if i+1 >= len(tag) || tag[i] != ':' {
+			return errTagSyntax
+		}
+		if tag[i+1] != '"' {
+			return errTagValueSyntax
+		}
+		tag = tag[i+1:]
+
+		i = 1
+		for i < len(tag) && tag[i] != '"' {
+			if tag[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(tag) {
+			return errTagValueSyntax
+		}
+		if _, err := strconv.Unquote(tag[:i+1]); err != nil {
+			return errTagValueSyntax
+		}
+		tag = tag[i+1:]
+
+		if tag != "" && tag[0] != ' ' {
+			return errTagSpace
+		}
+	}
+
+	return nil
+}
@@ -1,6 +1,9 @@
 package apig
 
-import "testing"
+import (
+	"path/filepath"
+	"testing"
+)
 
 func TestValidateForeignKey(t *testing.T) {
 	model := &Model{
@@ -39,3 +42,50 @@ func TestValidateForeignKey(t *testing.T) {
 		t.Fatalf("Incorrect result. expected: false, actual: %v", result)
 	}
 }
+
+func TestValidateTag(t *testing.T) {
+	valid := []string{
+		``,
+		`json:"id"`,
+		`gorm:"primary_key;AUTO_INCREMENT" json:"id,omitempty" form:"id"`,
+	}
+
+	for _, tag := range valid {
+		if err := validateTag(tag); err != nil {
+			t.Fatalf("Error should not be raised for %s: %s", tag, err)
+		}
+	}
+
+	invalid := []string{
+		`json:"id",form:"id"`,
+		`json:id`,
+		`json`,
+		`json:"id`,
+		`:"id"`,
+	}
+
+	for _, tag := range invalid {
+		if err := validateTag(tag); err == nil {
+			t.Fatalf("Error should be raised for %s", tag)
+		}
+	}
+}
+
+func TestValidateStorage(t *testing.T) {
+	models, err := parseModel([]string{filepath.Join("testdata", "json", "models.go")})
+	if err != nil {
+		t.Fatalf("Failed to parse model file. error: %s", err)
+	}
+
+	invalid := map[string]bool{
+		"Settings": true,
+		"Scores":   true,
+		"Extra":    true,
+	}
+
+	for _, field := range models[0].Fields {
+		if err := validateStorage(field); (err != nil) != invalid[field.Name] {
+			t.Fatalf("Incorrect result of %s. expected error: %v, actual: %v", field.Name, invalid[field.Name], err)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 
 	"github.com/mitchellh/cli"
+	"github.com/wantedly/apig/apig"
 	"github.com/wantedly/apig/command"
 )
 
@@ -28,6 +29,9 @@ func Run(args []string) int {
 
 func RunCustom(args []string, commands map[string]cli.CommandFactory) int {
 
+	// The files generated by another version of apig are generated again.
+	apig.Version = Version
+
 	// Get the command line args. We shortcut "--version" and "-v" to
 	// just show the version.
 	for _, arg := range args {
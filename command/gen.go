@@ -3,6 +3,7 @@ package command
 import (
 	"flag"
 	"fmt"
+	"go/scanner"
 	"os"
 	"path/filepath"
 	"strings"
@@ -14,12 +15,30 @@ import (
 const (
 	modelDir   = "models"
 	targetFile = "main.go"
+	configFile = "apig.yml"
 )
 
 type GenCommand struct {
 	Meta
 
-	all bool
+	all       bool
+	force     bool
+	dryRun    bool
+	check     bool
+	templates string
+	plugins   stringsFlag
+}
+
+// stringsFlag is the flag which can be given multiple times.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
 }
 
 func (c *GenCommand) Run(args []string) int {
@@ -28,7 +47,7 @@ func (c *GenCommand) Run(args []string) int {
 		fmt.Fprintln(os.Stderr, err)
 		return 1
 	}
-	if !util.FileExists(filepath.Join(wd, targetFile)) || !util.FileExists(filepath.Join(wd, modelDir)) {
+	if !util.FileExists(filepath.Join(wd, configFile)) && !util.FileExists(filepath.Join(wd, targetFile)) || !util.FileExists(filepath.Join(wd, modelDir)) {
 		fmt.Fprintf(os.Stderr, `%s is not project root. Please move.
 `, wd)
 		return 1
@@ -38,7 +57,69 @@ func (c *GenCommand) Run(args []string) int {
 		fmt.Fprintln(os.Stderr, err)
 		return 1
 	}
-	return apig.Generate(wd, modelDir, targetFile, c.all)
+
+	generators := make(map[string]apig.Generator)
+
+	for _, plugin := range c.plugins {
+		generators[filepath.Base(plugin)] = apig.PluginGenerator(plugin)
+	}
+
+	result, err := apig.Generate(&apig.Options{
+		OutDir:     wd,
+		ModelDir:   modelDir,
+		TargetFile: targetFile,
+		Templates:  c.templates,
+		All:        c.all,
+		Force:      c.force,
+		DryRun:     c.dryRun || c.check,
+		Generators: generators,
+	})
+	if err != nil {
+		if list, ok := err.(scanner.ErrorList); ok {
+			scanner.PrintError(os.Stderr, list)
+			fmt.Fprintln(os.Stderr, "Failed to read model files. Please fix the errors above.")
+		} else {
+			fmt.Fprintln(os.Stderr, err)
+		}
+
+		return 1
+	}
+
+	for _, warning := range result.Warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+
+	if c.check {
+		changed := result.Changed()
+
+		if len(changed) == 0 {
+			fmt.Println("===> All files are up to date.")
+			return 0
+		}
+
+		fmt.Fprintf(os.Stderr, "%d files are out of date. Please run apig gen.\n", len(changed))
+
+		for _, f := range changed {
+			fmt.Fprintf(os.Stderr, "\t%s\n", f.Path)
+		}
+
+		return 1
+	}
+
+	printFiles(wd, result.Files, c.dryRun)
+
+	if conflicts := result.Conflicts(); len(conflicts) > 0 {
+		fmt.Fprintf(os.Stderr, "%d files were not generated because of the conflicts with the edits by hand. Please merge the changes by yourself or run apig gen -force.\n", len(conflicts))
+		return 1
+	}
+
+	if c.dryRun {
+		fmt.Println("===> Dry run, no files were written.")
+		return 0
+	}
+
+	fmt.Println("===> Generated...")
+	return 0
 }
 
 func (c *GenCommand) parseArgs(args []string) error {
@@ -46,6 +127,11 @@ func (c *GenCommand) parseArgs(args []string) error {
 
 	flag.BoolVar(&c.all, "a", false, "Generate all skelton")
 	flag.BoolVar(&c.all, "all", false, "Generate all skelton")
+	flag.BoolVar(&c.force, "force", false, "Overwrite files edited by hand instead of merging the edits")
+	flag.BoolVar(&c.dryRun, "dry-run", false, "Print the diff of the files without writing them")
+	flag.BoolVar(&c.check, "check", false, "Exit with status 1 when the files are out of date without writing them")
+	flag.StringVar(&c.templates, "templates", "", "Directory of the templates overriding the embedded ones")
+	flag.Var(&c.plugins, "plugin", "Plugin executable generating extra files")
 
 	if err := flag.Parse(args); err != nil {
 		return err
@@ -66,6 +152,11 @@ Usage: apig [options] gen
 
 Options:
   -all, -a          Generate all boilerplate including new command generated code
+  -force            Overwrite files edited by hand instead of merging the edits
+  -dry-run          Print the diff of the files without writing them
+  -check            Exit with status 1 when the files are out of date without writing them
+  -templates=dir    Directory of the templates overriding the embedded ones (default: .apig/templates)
+  -plugin=command   Plugin executable generating extra files, e.g. apig-gen-foo (can be given multiple times)
 `
 	return strings.TrimSpace(helpText)
 }
@@ -1,11 +1,63 @@
 package command
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/mitchellh/cli"
+	"github.com/wantedly/apig/apig"
 )
 
+const userModel = `package models
+
+type User struct {
+	ID   uint   ` + "`json:\"id\" gorm:\"primary_key\"`" + `
+	Name string ` + "`json:\"name\"`" + `
+}
+`
+
 func TestGenCommand_implement(t *testing.T) {
 	var _ cli.Command = &GenCommand{}
 }
+
+func TestGenCommand_Check(t *testing.T) {
+	dir, err := ioutil.TempDir("", "check")
+	if err != nil {
+		t.Fatal("Failed to create tempdir")
+	}
+	defer os.RemoveAll(dir)
+
+	outDir := filepath.Join(dir, "api-server")
+
+	if _, err := apig.Skeleton(&apig.SkeletonOptions{OutDir: outDir, ImportPath: "github.com/wantedly/api-server", Namespace: "api", Database: "sqlite"}); err != nil {
+		t.Fatalf("Error should not be raised: %s", err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(outDir, "models", "user.go"), []byte(userModel), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	if err := os.Chdir(outDir); err != nil {
+		t.Fatal(err)
+	}
+
+	if code := (&GenCommand{}).Run([]string{"-check", "-all"}); code != 1 {
+		t.Fatalf("Incorrect exit status before the generation. expected: 1, actual: %d", code)
+	}
+
+	if code := (&GenCommand{}).Run([]string{"-all"}); code != 0 {
+		t.Fatalf("Incorrect exit status of the generation. expected: 0, actual: %d", code)
+	}
+
+	if code := (&GenCommand{}).Run([]string{"-check", "-all"}); code != 0 {
+		t.Fatalf("Incorrect exit status of the up-to-date project. expected: 0, actual: %d", code)
+	}
+}
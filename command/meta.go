@@ -1,8 +1,36 @@
 package command
 
-import "github.com/mitchellh/cli"
+import (
+	"path/filepath"
+
+	"github.com/mitchellh/cli"
+	"github.com/wantedly/apig/apig"
+	"github.com/wantedly/apig/msg"
+)
 
 // Meta contain the meta-option that nearly all subcommand inherited.
 type Meta struct {
 	Ui cli.Ui
 }
+
+// printFiles prints what the generation did to the files in outDir, and their diffs if diff is true.
+func printFiles(outDir string, files []*apig.File, diff bool) {
+	for _, f := range files {
+		path := filepath.Join(outDir, filepath.FromSlash(f.Path))
+
+		switch f.Status {
+		case apig.StatusIdentical:
+			msg.Printf("\t\x1b[34m%s\x1b[0m %s\n", f.Status, path)
+		case apig.StatusKeep:
+			msg.Printf("\t\x1b[34m%s\x1b[0m %s (%s)\n", f.Status, path, f.Reason)
+		case apig.StatusConflict:
+			msg.Printf("\t\x1b[31m%s\x1b[0m %s (%s, use -force to overwrite)\n", f.Status, path, f.Reason)
+		default:
+			msg.Printf("\t\x1b[32m%s\x1b[0m %s\n", f.Status, path)
+
+			if diff {
+				msg.Printf("%s", f.Diff())
+			}
+		}
+	}
+}
@@ -5,10 +5,12 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/tcnksm/go-gitconfig"
 	"github.com/wantedly/apig/apig"
+	"github.com/wantedly/apig/msg"
 )
 
 const (
@@ -24,6 +26,8 @@ type NewCommand struct {
 	project   string
 	namespace string
 	database  string
+	module    string
+	templates string
 }
 
 func (c *NewCommand) Run(args []string) int {
@@ -32,13 +36,35 @@ func (c *NewCommand) Run(args []string) int {
 		return 1
 	}
 
-	gopath := os.Getenv("GOPATH")
-	if gopath == "" {
-		fmt.Fprintln(os.Stderr, "Error: $GOPATH is not found")
+	wd, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
 		return 1
 	}
 
-	return apig.Skeleton(gopath, c.vcs, c.username, c.project, c.namespace, c.database)
+	module := c.module
+	if module == "" {
+		module = c.vcs + "/" + c.username + "/" + c.project
+	}
+
+	outDir := filepath.Join(wd, c.project)
+
+	result, err := apig.Skeleton(&apig.SkeletonOptions{
+		OutDir:     outDir,
+		ImportPath: module,
+		Namespace:  c.namespace,
+		Database:   c.database,
+		Templates:  c.templates,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	printFiles(outDir, result.Files, false)
+
+	msg.Printf("===> Created %s\n===> Please run go mod tidy in it to fetch the dependencies.\n", outDir)
+	return 0
 }
 
 func (c *NewCommand) parseArgs(args []string) error {
@@ -51,6 +77,9 @@ func (c *NewCommand) parseArgs(args []string) error {
 	flag.StringVar(&c.namespace, "namespace", "", "Namespace of API")
 	flag.StringVar(&c.database, "d", defaultDatabase, "Database engine [sqlite,postgres,mysql]")
 	flag.StringVar(&c.database, "database", defaultDatabase, "Database engine [sqlite,postgres,mysql]")
+	flag.StringVar(&c.module, "m", "", "Module path")
+	flag.StringVar(&c.module, "module", "", "Module path")
+	flag.StringVar(&c.templates, "templates", "", "Directory of the templates overriding the embedded ones")
 
 	if err := flag.Parse(args); err != nil {
 		return err
@@ -63,7 +92,7 @@ func (c *NewCommand) parseArgs(args []string) error {
 		return errors.New("Please specify project name.")
 	}
 
-	if c.username == "" {
+	if c.username == "" && c.module == "" {
 		var err error
 		c.username, err = gitconfig.GithubUser()
 		if err != nil {
@@ -85,11 +114,13 @@ func (c *NewCommand) Help() string {
 	helpText := `
 Usage: apig new [options] PROJECTNAME
 
-  Generate go project and its boilerplate
+  Generate go module project and its boilerplate in PROJECTNAME directory
 
 Options:
   -database=database, -d     Database engine [sqlite,postgres,mysql] (default: sqlite)
+  -module=path, -m           Module path (default: VCS/USER/PROJECTNAME)
   -namespace=namepace, -n    Namespace of API (default: "" (blank string))
+  -templates=dir             Directory of the templates overriding the embedded ones
   -user=name, -u             Username of VCS (default: username of github in .gitconfig)
   -vcs=name                  Version controll system to use (default: github.com)
 `